@@ -1,41 +1,5 @@
-/**
- * Gets the scouters of a given match
- * 
- * @param {*} match The match number
- * @param {*} color The driverstation color (Red/Blue)
- * @param {*} driverStation The driverstation number (1-3)
- * @returns The scouters
- */
-function GETSCOUTER(match, color, driverStation) {
-    if (!Number.isInteger(match) || match < 1 ) {
-      return "Please enter a valid match"
-    }
-  
-    var colorAsString = color.toUpperCase();
-  
-    if (colorAsString != "RED" && colorAsString != "BLUE") {
-      return "Please enter a valid color"
-    }
-  
-    if (!Number.isInteger(driverStation) || driverStation < 1 || driverStation > 3) {
-      return "Please enter a valid Driverstation"
-    }
-  
-    var formData =`{"Match":${match}, "isBlue":${colorAsString == "BLUE"}, "DriverStation":${driverStation}}`;
-  
-  // Because payload is a JavaScript object, it is interpreted as
-  // as form data. (No need to specify contentType; it automatically
-  // defaults to either 'application/x-www-form-urlencoded'
-  // or 'multipart/form-data')
-  var options = {
-    'method' : 'get',
-    'payload' : formData
-  };
-  
-  var response = UrlFetchApp.fetch('https://tagciccone.com/scouterLookup', options); //TODO: Probably change this
-  return response.getContentText()
-  }
-  
+// GETSCOUTER() used to live here. The server keeps a Coverage tab with every match's scouters instead.
+
   const quotes = [
     'Drink water.',
     'Slow down!',
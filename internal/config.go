@@ -17,6 +17,7 @@ var JsonArchiveDirectory string
 var JsonErroredDirectory string
 var JsonDiscardedDirectory string
 var JsonPitWrittenDirectory string
+var JsonPrescoutedDirectory string
 
 // The default domain to be allowed to query the backend for CORS
 // (e.g. the frontend). This allows *all* domains to query the backend, and
@@ -60,7 +61,17 @@ type GeneralConfigs struct {
 	DomainName         string             `yaml:"DomainName"`         // The domain name that matches to the server's IP
 	FrontendDomain     string             `yaml:"FrontendDomain"`     // The domain hosting the GreenScout frontend (for CORS)
 	UsingMultiScouting bool               `yaml:"UsingMultiScouting"` // If multi-scouting is enabled
+	IngestWorkers      int                `yaml:"IngestWorkers"`      // How many submissions can be processed at once. Entries for the same match and driverstation are always processed one at a time.
+	MergeStrategies    map[string]string  `yaml:"MergeStrategies"`    // How each field is merged when multi-scouting, by json path (auto.scores: median). See MergeStrategy for the options. Fields left out keep their defaults.
+	WeighByReliability bool               `yaml:"WeighByReliability"` // If means and modes weigh each multi-scouter's answers by how reliable they have been
 	SpreadSheetID      string             `yaml:"SpreadSheetID"`      // The ID to the google sheet to be used
+	OutputSinks        []OutputSinkConfig `yaml:"OutputSinks"`        // Everywhere processed data is written. Defaults to just the google sheet.
+	SheetsQuota        int                `yaml:"SheetsQuota"`        // How many calls a minute can be made to the sheets API. Defaults to 60, google's limit per user.
+	SheetsBackend      string             `yaml:"SheetsBackend"`      // google (the default) or fake, to use an in-process stand-in for google sheets that needs no credentials or network
+	FakeSheetsFile     string             `yaml:"FakeSheetsFile"`     // Where the fake sheets backend saves its spreadsheets. Left empty, they are only kept in memory.
+	ReconcileMinutes   int                `yaml:"ReconcileMinutes"`   // How often the sheet is checked against matches.db, in minutes. Defaults to 10, and anything negative turns it off.
+	NewSheetPerEvent   bool               `yaml:"NewSheetPerEvent"`   // If every event gets a spreadsheet of its own, created when the event key changes
+	SheetLayouts       SheetLayouts       `yaml:"SheetLayouts"`       // The columns of the RawData, PitScouting and Prescouting tabs. Tabs left out keep their season's columns.
 	PathToDatabases    string             `yaml:"PathToDatabases"`    // The filepath to the directory containing the users and authentication databases.
 	RuntimeDirectory   string             `yaml:"RuntimeDirectory"`
 	JsonDirectory      string             `yaml:"JsonDirectory"`
@@ -0,0 +1,277 @@
+package internal
+
+// The Coverage tab, showing who was assigned to every match and driverstation, who actually scouted it, and what's missing.
+// It's kept up to date by the server, instead of the spreadsheet asking /scouterLookup once per cell.
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often the Coverage tab is refreshed if anything changed
+const kCoverageRefreshInterval = 10 * time.Second
+
+// Coverage status enum
+const (
+	CoverageCovered    = "covered"    // Scouted by someone assigned to it
+	CoverageSubstitute = "substitute" // Scouted, but not by anyone assigned to it
+	CoverageMissing    = "missing"    // Not scouted, even though later matches have been
+	CoverageUpcoming   = "upcoming"   // Not scouted yet, and someone is assigned to it
+	CoverageUnassigned = "unassigned" // Not scouted yet, and nobody is assigned to it
+)
+
+// The headers of the Coverage tab
+var coverageColumns = []string{"Match", "Driver Station", "Team", "Assigned", "Submitted", "Status"}
+
+// One match and driverstation
+type coverageSlot struct {
+	match  uint
+	offset int // The driverstation, 0-5 from red1 to blue3 like GetDSOffset()
+}
+
+// What has been written to the Coverage tab, held in memory so only rows that changed are written again
+type coverageCache struct {
+	mutex       sync.Mutex
+	stale       bool
+	event       string           // The event the rows were written for
+	spreadsheet string           // The spreadsheet the rows were written to
+	written     map[int][]string // How every written row reads on the sheet, by row number
+}
+
+// The Coverage tab as it was last written
+var coverage = coverageCache{stale: true}
+
+// Marks the Coverage tab as needing a refresh. Cheap enough to call on every change, since refreshes are batched.
+func MarkCoverageStale() {
+	coverage.mutex.Lock()
+	coverage.stale = true
+	coverage.mutex.Unlock()
+}
+
+// Forgets what was written to the Coverage tab, so every row is written again on the next refresh
+func resetCoverage() {
+	coverage.mutex.Lock()
+	coverage.written = nil
+	coverage.stale = true
+	coverage.mutex.Unlock()
+}
+
+// Refreshes the Coverage tab every kCoverageRefreshInterval, if anything changed since the last refresh
+func RunCoverageRefresh() {
+	ticker := time.NewTicker(kCoverageRefreshInterval)
+	for range ticker.C {
+		if err := RefreshCoverage(); err != nil {
+			LogError(err, "Problem refreshing the Coverage tab")
+		}
+	}
+}
+
+// Writes every row of the Coverage tab that changed since it was last written, in one batch.
+// Does nothing if nothing was marked stale since the last refresh. The cache isn't locked while writing,
+// so marking it stale never waits on the sheet.
+func RefreshCoverage() error {
+	coverage.mutex.Lock()
+	if !coverage.stale {
+		coverage.mutex.Unlock()
+		return nil
+	}
+
+	event := GetCurrentEvent()
+	spreadsheet := SpreadsheetId
+	if coverage.written == nil || coverage.event != event || coverage.spreadsheet != spreadsheet {
+		coverage.written = make(map[int][]string)
+		coverage.event = event
+		coverage.spreadsheet = spreadsheet
+	}
+
+	var changed []SinkRow
+	for _, row := range expectedCoverageRows(event) {
+		if !slices.Equal(cellTexts(row.Values), coverage.written[row.Row]) {
+			changed = append(changed, row)
+		}
+	}
+
+	coverage.stale = false
+	coverage.mutex.Unlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	err := WriteSinkRows(TabCoverage, changed)
+
+	coverage.mutex.Lock()
+	defer coverage.mutex.Unlock()
+
+	if err != nil {
+		coverage.stale = true
+		return err
+	}
+
+	// Unless it was reset or moved to another event or sheet while writing
+	if coverage.written != nil && coverage.event == event && coverage.spreadsheet == spreadsheet {
+		for _, row := range changed {
+			coverage.written[row.Row] = cellTexts(row.Values)
+		}
+	}
+	return nil
+}
+
+// Works out every row of the Coverage tab of the current event, headers included: one row per match and driverstation,
+// in the same order as RawData, for every match in the schedule or that anyone was assigned to or scouted.
+func expectedCoverageRows(event string) []SinkRow {
+	schedule, _ := GetSchedule()
+	assigned := coverageAssignments()
+	submitted := coverageSubmitters(event)
+
+	var matches, lastScouted uint
+	for match := range schedule {
+		matches = max(matches, match)
+	}
+	for slot := range assigned {
+		matches = max(matches, slot.match)
+	}
+	for slot := range submitted {
+		matches = max(matches, slot.match)
+		lastScouted = max(lastScouted, slot.match)
+	}
+
+	rows := []SinkRow{{Row: 1, Values: CurrentSeason().Headers(TabCoverage)}}
+	for match := uint(1); match <= matches; match++ {
+		for offset := range 6 {
+			slot := coverageSlot{match: match, offset: offset}
+			isBlue := offset >= 3
+			number := offset%3 + 1
+
+			var team interface{} = ""
+			color := "Red"
+			if isBlue {
+				color = "Blue"
+			}
+			if alliance := schedule[match][color]; len(alliance) >= number {
+				team = alliance[number-1]
+			}
+
+			rows = append(rows, SinkRow{
+				Row: 2 + int(match-1)*6 + offset,
+				Values: []interface{}{
+					match,
+					GetDSString(isBlue, uint(number)),
+					team,
+					strings.Join(assigned[slot], ", "),
+					strings.Join(submitted[slot], ", "),
+					coverageStatus(assigned[slot], submitted[slot], match < lastScouted),
+				},
+			})
+		}
+	}
+
+	return rows
+}
+
+// Works out the status of one match and driverstation
+func coverageStatus(assigned []string, submitted []string, played bool) string {
+	if len(submitted) > 0 {
+		if len(assigned) == 0 {
+			return CoverageCovered
+		}
+		for _, scouter := range submitted {
+			if slices.ContainsFunc(assigned, func(name string) bool { return strings.EqualFold(name, scouter) }) {
+				return CoverageCovered
+			}
+		}
+		return CoverageSubstitute
+	}
+
+	if played {
+		return CoverageMissing
+	}
+	if len(assigned) > 0 {
+		return CoverageUpcoming
+	}
+	return CoverageUnassigned
+}
+
+// Gets everyone assigned to every match and driverstation from the scouter schedules in scout.db.
+// Each range of a schedule is [driverstation offset, first match, last match].
+func coverageAssignments() map[coverageSlot][]string {
+	assigned := make(map[coverageSlot][]string)
+	if scoutDB == nil {
+		return assigned
+	}
+
+	rows, err := scoutDB.Query("select username, schedule from individuals")
+	if err != nil {
+		LogError(err, "Problem reading scouter schedules for the Coverage tab")
+		return assigned
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, schedule string
+		if scanErr := rows.Scan(&name, &schedule); scanErr != nil {
+			LogError(scanErr, "Problem scanning a scouter schedule")
+			continue
+		}
+
+		var ranges ScoutRanges
+		if unmarshalErr := json.Unmarshal([]byte(schedule), &ranges); unmarshalErr != nil {
+			LogErrorf(unmarshalErr, "Problem unmarshalling the schedule of %v", name)
+			continue
+		}
+
+		for _, scoutRange := range ranges.Ranges {
+			offset, first, last := scoutRange[0], scoutRange[1], scoutRange[2]
+			if offset < 0 || offset > 5 || first < 1 {
+				continue
+			}
+			for match := first; match <= last; match++ {
+				slot := coverageSlot{match: uint(match), offset: offset}
+				if !slices.Contains(assigned[slot], name) {
+					assigned[slot] = append(assigned[slot], name)
+				}
+			}
+		}
+	}
+
+	return assigned
+}
+
+// Gets everyone whose entry for every match and driverstation of an event has been written, oldest first
+func coverageSubmitters(event string) map[coverageSlot][]string {
+	submitted := make(map[coverageSlot][]string)
+
+	rows, err := matchDB.Query(
+		"select matchnum, isblue, station, scouter from entries where event = ? and state = ? and prescouting = 0 order by received",
+		event, EntryWritten,
+	)
+	if err != nil {
+		LogErrorf(err, "Problem looking up the scouters of %v", event)
+		return submitted
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var match uint
+		var isBlue bool
+		var station int
+		var scouter string
+		if scanErr := rows.Scan(&match, &isBlue, &station, &scouter); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to scouter lookup")
+			continue
+		}
+		if match < 1 || station < 1 || station > 3 {
+			continue
+		}
+
+		slot := coverageSlot{match: match, offset: GetDSOffset(GetDSString(isBlue, uint(station)))}
+		if scouter != "" && !slices.Contains(submitted[slot], scouter) {
+			submitted[slot] = append(submitted[slot], scouter)
+		}
+	}
+
+	return submitted
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+// An output sink whose row writes wait until they are released
+type blockingSink struct {
+	writes  chan []SinkRow
+	release chan struct{}
+}
+
+func (sink *blockingSink) WriteRows(tab string, rows []SinkRow) error {
+	sink.writes <- rows
+	<-sink.release
+	return nil
+}
+
+func (sink *blockingSink) AppendRows(string, int, [][]interface{}) error { return nil }
+
+func (sink *blockingSink) ClearTabs([]string) error { return nil }
+
+func TestRefreshCoverageDoesNotLockWhileWriting(t *testing.T) {
+	setupTestEnvironment(t)
+
+	sink := &blockingSink{writes: make(chan []SinkRow, 2), release: make(chan struct{})}
+	setupTestSink(t, sink)
+
+	resetCoverage()
+	refreshed := make(chan error)
+	go func() { refreshed <- RefreshCoverage() }()
+
+	rows := <-sink.writes
+	if len(rows) == 0 || rows[0].Row != 1 {
+		t.Fatalf("first refresh wrote %v, want the headers", rows)
+	}
+
+	marked := make(chan struct{})
+	go func() {
+		MarkCoverageStale()
+		close(marked)
+	}()
+	select {
+	case <-marked:
+	case <-time.After(time.Second):
+		t.Fatal("marking coverage stale waited on the sheet")
+	}
+
+	close(sink.release)
+	if err := <-refreshed; err != nil {
+		t.Fatal(err)
+	}
+
+	// Marked stale while writing, but nothing changed, so nothing is written again
+	if err := RefreshCoverage(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.writes) != 0 {
+		t.Errorf("unchanged rows written again: %v", <-sink.writes)
+	}
+}
+
+func TestCoverageStatus(t *testing.T) {
+	cases := []struct {
+		assigned, submitted []string
+		played              bool
+		want                string
+	}{
+		{[]string{"a"}, []string{"A"}, true, CoverageCovered},
+		{nil, []string{"b"}, false, CoverageCovered},
+		{[]string{"a"}, []string{"b"}, true, CoverageSubstitute},
+		{[]string{"a"}, nil, true, CoverageMissing},
+		{[]string{"a"}, nil, false, CoverageUpcoming},
+		{nil, nil, false, CoverageUnassigned},
+	}
+
+	for _, testCase := range cases {
+		if got := coverageStatus(testCase.assigned, testCase.submitted, testCase.played); got != testCase.want {
+			t.Errorf("coverageStatus(%v, %v, %v) = %v, want %v", testCase.assigned, testCase.submitted, testCase.played, got, testCase.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package internal
+
+// An output sink that keeps every tab in its own csv file, for events without Google access
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Writes each tab to TAB.csv in its directory. The first column of the file is column B of the sheet.
+type csvSink struct {
+	directory string
+	mutex     sync.Mutex // Rows are written by reading and rewriting the whole file, so only one write can happen at a time
+}
+
+// Creates a csv sink writing to the passed in directory, creating it if it doesn't exist
+func newCSVSink(directory string) (*csvSink, error) {
+	if mkErr := os.MkdirAll(directory, 0755); mkErr != nil {
+		return nil, mkErr
+	}
+	return &csvSink{directory: directory}, nil
+}
+
+// What empty rows are written as. A single empty field would be written as an empty line, which csv readers skip, throwing off the row numbers.
+var blankCSVRecord = []string{"", ""}
+
+// Reads every record of a tab. Tabs that haven't been written yet are empty.
+func (sink *csvSink) read(tab string) ([][]string, error) {
+	file, openErr := os.Open(filepath.Join(sink.directory, tab+".csv"))
+	if errors.Is(openErr, os.ErrNotExist) {
+		return nil, nil
+	} else if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// Replaces a tab with the passed in records, through a temporary file so a crash can't leave it half written
+func (sink *csvSink) write(tab string, records [][]string) error {
+	path := filepath.Join(sink.directory, tab+".csv")
+
+	file, createErr := os.Create(path + ".tmp")
+	if createErr != nil {
+		return createErr
+	}
+
+	writer := csv.NewWriter(file)
+	writeErr := writer.WriteAll(records)
+	closeErr := file.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// Turns a row's cells into csv fields
+func csvRecord(values []interface{}) []string {
+	var record []string
+	for _, value := range values {
+		record = append(record, fmt.Sprint(value))
+	}
+	return record
+}
+
+// Writes over the passed in rows, padding the file with empty rows if it isn't long enough
+func (sink *csvSink) WriteRows(tab string, rows []SinkRow) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	records, readErr := sink.read(tab)
+	if readErr != nil {
+		return readErr
+	}
+
+	for _, row := range rows {
+		if row.Row < 1 {
+			return fmt.Errorf("invalid row %v", row.Row)
+		}
+		for len(records) < row.Row {
+			records = append(records, blankCSVRecord)
+		}
+		records[row.Row-1] = csvRecord(row.Values)
+	}
+
+	return sink.write(tab, records)
+}
+
+// Adds rows after the last one with anything in it, ignoring where the data starts
+func (sink *csvSink) AppendRows(tab string, from int, values [][]interface{}) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	records, readErr := sink.read(tab)
+	if readErr != nil {
+		return readErr
+	}
+
+	last := len(records)
+	for last > 1 && isBlankRecord(records[last-1]) {
+		last--
+	}
+	if last == 0 {
+		records = [][]string{blankCSVRecord} // Keep row 1 for the header
+		last = 1
+	}
+	records = records[:last]
+
+	for _, row := range values {
+		records = append(records, csvRecord(row))
+	}
+
+	return sink.write(tab, records)
+}
+
+// Returns if a record has nothing in it
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if field != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Cuts every tab down to its header
+func (sink *csvSink) ClearTabs(tabs []string) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	for _, tab := range tabs {
+		records, readErr := sink.read(tab)
+		if readErr != nil {
+			return readErr
+		}
+		if len(records) > 1 {
+			records = records[:1]
+		}
+		if writeErr := sink.write(tab, records); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return nil
+}
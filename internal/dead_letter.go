@@ -0,0 +1,197 @@
+package internal
+
+// Retrying of failed submissions and dead-lettering of the ones that give up
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/api/googleapi"
+)
+
+// How many times a submission will be attempted before it is dead-lettered
+const kMaxIngestAttempts = 6
+
+// The delay before the first retry. Every retry after doubles it, up to kMaxRetryDelay.
+const kBaseRetryDelay = 10 * time.Second
+
+// The longest a submission will wait between attempts
+const kMaxRetryDelay = 5 * time.Minute
+
+// The extension given to the sidecar records written next to dead-lettered files in Errored
+const DeadLetterExtension = ".error"
+
+// The record written next to a file that has been dead-lettered
+type DeadLetterRecord struct {
+	File         string    `json:"File"`         // The name of the dead-lettered file
+	Error        string    `json:"Error"`        // The last error that occurred while processing the file
+	Transient    bool      `json:"Transient"`    // If the last error was one that could go away on its own (rate limiting, outages, network problems)
+	Attempts     int       `json:"Attempts"`     // How many times processing was attempted
+	FirstAttempt time.Time `json:"FirstAttempt"` // When processing was first attempted
+	LastAttempt  time.Time `json:"LastAttempt"`  // When processing was last attempted
+	DeadLettered time.Time `json:"DeadLettered"` // When the file was given up on
+}
+
+// The attempts made on a file that is waiting to be retried
+type retryState struct {
+	attempts     int
+	firstAttempt time.Time
+	lastAttempt  time.Time
+	retryAfter   time.Time
+}
+
+// Retry states of files in the In directory, by file name.
+// These are only held in memory, so a restart gives every waiting file a fresh set of attempts.
+var retries = struct {
+	mutex  sync.Mutex
+	states map[string]*retryState
+}{states: make(map[string]*retryState)}
+
+// Returns if the error is one that could go away by simply trying again later
+func isTransientError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case 408, 429, 500, 502, 503, 504:
+			return true
+		}
+		return false
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	// Only network problems that come and go, not bad URLs or certificates that will fail the same way every time
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// Returns if a file is waiting out its backoff and shouldn't be processed yet
+func isWaitingForRetry(fileName string) bool {
+	retries.mutex.Lock()
+	defer retries.mutex.Unlock()
+
+	state, ok := retries.states[fileName]
+	return ok && time.Now().Before(state.retryAfter)
+}
+
+// Gets a copy of the retry state of a file, returning false if it hasn't failed yet
+func getRetryState(fileName string) (retryState, bool) {
+	retries.mutex.Lock()
+	defer retries.mutex.Unlock()
+
+	state, ok := retries.states[fileName]
+	if !ok {
+		return retryState{}, false
+	}
+	return *state, true
+}
+
+// Gets the number of files waiting out their backoff
+func retryingCount() int {
+	retries.mutex.Lock()
+	defer retries.mutex.Unlock()
+
+	return len(retries.states)
+}
+
+// Forgets any retry state of a file once it has been processed
+func clearRetryState(fileName string) {
+	retries.mutex.Lock()
+	delete(retries.states, fileName)
+	retries.mutex.Unlock()
+}
+
+// Records a failed attempt to process a file in the In directory.
+// Transient errors leave the file in place to be retried with exponential backoff, anything else
+// (or running out of attempts) moves it to Errored with a sidecar DeadLetterRecord.
+func handleIngestFailure(fileName string, err error) {
+	now := time.Now()
+	transient := isTransientError(err)
+
+	retries.mutex.Lock()
+	state, ok := retries.states[fileName]
+	if !ok {
+		state = &retryState{firstAttempt: now}
+		retries.states[fileName] = state
+	}
+	state.attempts++
+	state.lastAttempt = now
+
+	if transient && state.attempts < kMaxIngestAttempts {
+		delay := kBaseRetryDelay << (state.attempts - 1)
+		if delay > kMaxRetryDelay {
+			delay = kMaxRetryDelay
+		}
+		state.retryAfter = now.Add(delay)
+		retries.mutex.Unlock()
+
+		LogMessagef("Attempt %v at processing %v failed (%v), retrying in %v", state.attempts, fileName, err, delay)
+		return
+	}
+
+	record := DeadLetterRecord{
+		File:         fileName,
+		Error:        err.Error(),
+		Transient:    transient,
+		Attempts:     state.attempts,
+		FirstAttempt: state.firstAttempt,
+		LastAttempt:  state.lastAttempt,
+		DeadLettered: now,
+	}
+	delete(retries.states, fileName)
+	retries.mutex.Unlock()
+
+	deadLetter(JsonInDirectory, record)
+}
+
+// Moves a file from the passed in directory to Errored and writes its DeadLetterRecord next to it.
+func deadLetter(fromDirectory string, record DeadLetterRecord) {
+	erroredPath := filepath.Join(JsonErroredDirectory, record.File)
+
+	if !MoveFile(filepath.Join(fromDirectory, record.File), erroredPath) {
+		LogMessagef("Unable to move %v to %v", filepath.Join(fromDirectory, record.File), erroredPath)
+		return
+	}
+	SetEntryState(record.File, EntryErrored)
+
+	recordBytes, marshalErr := json.MarshalIndent(record, "", "  ")
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem marshalling %v", record)
+		return
+	}
+
+	if writeErr := WriteFileWithPermissions(erroredPath+DeadLetterExtension, recordBytes); writeErr != nil {
+		LogErrorf(writeErr, "Problem writing %v", erroredPath+DeadLetterExtension)
+	}
+
+	LogMessagef("Gave up on %v after %v attempt(s), moved to %v: %v", record.File, record.Attempts, erroredPath, record.Error)
+}
+
+// Reads the DeadLetterRecord of a file in Errored, returning false if it doesn't have one.
+func GetDeadLetterRecord(fileName string) (DeadLetterRecord, bool) {
+	var record DeadLetterRecord
+
+	recordBytes, readErr := readFileIfExists(filepath.Join(JsonErroredDirectory, fileName+DeadLetterExtension))
+	if readErr != nil || recordBytes == nil {
+		return record, false
+	}
+
+	if unmarshalErr := json.Unmarshal(recordBytes, &record); unmarshalErr != nil {
+		LogErrorf(unmarshalErr, "Problem unmarshalling dead letter record of %v", fileName)
+		return record, false
+	}
+
+	return record, true
+}
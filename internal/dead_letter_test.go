@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		err       error
+		transient bool
+	}{
+		{&googleapi.Error{Code: 429}, true},
+		{&googleapi.Error{Code: 503}, true},
+		{fmt.Errorf("writing row: %w", &googleapi.Error{Code: 500}), true},
+		{&googleapi.Error{Code: 400}, false},
+		{&googleapi.Error{Code: 403}, false},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{&url.Error{Op: "Post", URL: "https://sheets.googleapis.com", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}, true},
+		{&url.Error{Op: "Post", URL: "https://sheets.googleapis.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, true},
+		{&url.Error{Op: "Post", URL: "https://sheets.googleapis.com", Err: context.DeadlineExceeded}, true},
+		{&url.Error{Op: "Post", URL: "https://sheets.googleapis.com", Err: x509.UnknownAuthorityError{}}, false},
+		{&url.Error{Op: "parse", URL: "://sheets", Err: errors.New("missing protocol scheme")}, false},
+		{errors.New("invalid entry"), false},
+	}
+
+	for _, testCase := range cases {
+		if got := isTransientError(testCase.err); got != testCase.transient {
+			t.Errorf("isTransientError(%v) = %v, want %v", testCase.err, got, testCase.transient)
+		}
+	}
+}
+
+func TestTransientFailuresBackOffThenDeadLetter(t *testing.T) {
+	setupTestEnvironment(t)
+
+	fileName := "2026test_3_red1_1700000000000.json"
+	writeTestFile(t, filepath.Join(JsonInDirectory, fileName))
+	t.Cleanup(func() { clearRetryState(fileName) })
+
+	outage := &googleapi.Error{Code: 503}
+	for attempt := 1; attempt < kMaxIngestAttempts; attempt++ {
+		handleIngestFailure(fileName, outage)
+
+		state, ok := getRetryState(fileName)
+		if !ok || state.attempts != attempt {
+			t.Fatalf("attempt %v: retry state %+v, %v", attempt, state, ok)
+		}
+		wantDelay := min(kBaseRetryDelay<<(attempt-1), kMaxRetryDelay)
+		if delay := state.retryAfter.Sub(state.lastAttempt); delay != wantDelay {
+			t.Errorf("attempt %v: waiting %v, want %v", attempt, delay, wantDelay)
+		}
+		if !isWaitingForRetry(fileName) {
+			t.Errorf("attempt %v: not waiting for a retry", attempt)
+		}
+		if _, err := os.Stat(filepath.Join(JsonInDirectory, fileName)); err != nil {
+			t.Fatalf("attempt %v: file left In: %v", attempt, err)
+		}
+	}
+
+	handleIngestFailure(fileName, outage)
+
+	if _, ok := getRetryState(fileName); ok {
+		t.Error("retry state kept after dead-lettering")
+	}
+	if _, err := os.Stat(filepath.Join(JsonErroredDirectory, fileName)); err != nil {
+		t.Fatalf("file not moved to Errored: %v", err)
+	}
+
+	record, ok := GetDeadLetterRecord(fileName)
+	if !ok {
+		t.Fatal("no dead letter record")
+	}
+	if record.Attempts != kMaxIngestAttempts || !record.Transient || record.Error != outage.Error() {
+		t.Errorf("unexpected dead letter record %+v", record)
+	}
+}
+
+func TestPermanentFailureDeadLettersImmediately(t *testing.T) {
+	setupTestEnvironment(t)
+
+	fileName := "2026test_3_blue2_1700000000000.json"
+	writeTestFile(t, filepath.Join(JsonInDirectory, fileName))
+	t.Cleanup(func() { clearRetryState(fileName) })
+
+	handleIngestFailure(fileName, errors.New("row is too long"))
+
+	if _, err := os.Stat(filepath.Join(JsonErroredDirectory, fileName)); err != nil {
+		t.Fatalf("file not moved to Errored: %v", err)
+	}
+	record, ok := GetDeadLetterRecord(fileName)
+	if !ok || record.Attempts != 1 || record.Transient {
+		t.Errorf("unexpected dead letter record %+v, %v", record, ok)
+	}
+}
+
+// Writes an empty json object to a path
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("{}"), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
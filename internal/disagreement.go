@@ -0,0 +1,181 @@
+package internal
+
+// Finding where multi-scouters disagreed on a match, so strategy leads know which rows to double check
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// One scouter's answer for a field
+type ScouterValue struct {
+	Scouter string `json:"Scouter"` // Who answered
+	Value   any    `json:"Value"`   // What they answered. Lists are counted, and fields missing from their entry are null.
+}
+
+// One field the scouters of a match didn't agree on
+type Disagreement struct {
+	Field  string         `json:"Field"`            // The json path of the field, e.g. endgame.park
+	Spread *float64       `json:"Spread,omitempty"` // The difference between the highest and lowest answer, if the field is a number
+	Values []ScouterValue `json:"Values"`           // What every scouter answered
+}
+
+// Every field the scouters of one match and driverstation disagreed on
+type DisagreementReport struct {
+	Event         string         `json:"Event"`         // The event key
+	Match         uint           `json:"Match"`         // The match number
+	Replay        bool           `json:"Replay"`        // If it was the replay of the match
+	DriverStation string         `json:"DriverStation"` // The driverstation (red1..blue3)
+	Team          uint64         `json:"Team"`          // The team, as the first scouter saw it
+	Scouters      []string       `json:"Scouters"`      // Everyone who scouted it
+	Disagreements []Disagreement `json:"Disagreements"` // The fields they disagreed on
+	Compared      int            `json:"Compared"`      // How many fields were compared
+	Updated       time.Time      `json:"Updated"`       // When the match was last merged
+}
+
+// Compares every field of the passed in entries, which should all be for the same match and driverstation
+func BuildDisagreementReport(season *GameSeason, event string, entries []MatchEntry) DisagreementReport {
+	info := entries[0].Info()
+
+	report := DisagreementReport{
+		Event:         event,
+		Match:         info.Match.Number,
+		Replay:        info.Match.IsReplay,
+		DriverStation: GetDSString(info.DriverStation.IsBlue, uint(info.DriverStation.Number)),
+		Team:          info.TeamNumber,
+		Disagreements: []Disagreement{},
+		Updated:       time.Now(),
+	}
+
+	var flattened []map[string]any
+	var fields []string
+	for _, entry := range entries {
+		report.Scouters = append(report.Scouters, entry.Info().Scouter)
+
+		values := flattenEntry(entry)
+		for field := range values {
+			if !slices.Contains(fields, field) && !isUncompared(season, field) {
+				fields = append(fields, field)
+			}
+		}
+		flattened = append(flattened, values)
+	}
+	sort.Strings(fields)
+	report.Compared = len(fields)
+
+	for _, field := range fields {
+		var values []ScouterValue
+		agreed := true
+		for i, entryValues := range flattened {
+			values = append(values, ScouterValue{Scouter: report.Scouters[i], Value: entryValues[field]})
+			if !reflect.DeepEqual(entryValues[field], flattened[0][field]) {
+				agreed = false
+			}
+		}
+
+		if agreed {
+			continue
+		}
+
+		disagreement := Disagreement{Field: field, Values: values}
+		if spread, numeric := numericSpread(values); numeric {
+			disagreement.Spread = &spread
+		}
+		report.Disagreements = append(report.Disagreements, disagreement)
+	}
+
+	return report
+}
+
+// Flattens an entry into its json paths and their values. Lists are replaced with how long they are.
+func flattenEntry(entry any) map[string]any {
+	flattened := make(map[string]any)
+
+	entryBytes, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem encoding %v", entry)
+		return flattened
+	}
+
+	var decoded any
+	if unmarshalErr := json.Unmarshal(entryBytes, &decoded); unmarshalErr != nil {
+		LogErrorf(unmarshalErr, "Problem decoding %v", string(entryBytes))
+		return flattened
+	}
+
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		switch typed := value.(type) {
+		case map[string]any:
+			for key, child := range typed {
+				if path == "" {
+					walk(key, child)
+				} else {
+					walk(path+"."+key, child)
+				}
+			}
+		case []any:
+			flattened[path+".length"] = float64(len(typed))
+		default:
+			flattened[path] = typed
+		}
+	}
+	walk("", decoded)
+
+	return flattened
+}
+
+// Returns if a field is one the season doesn't compare between scouters
+func isUncompared(season *GameSeason, field string) bool {
+	for _, uncompared := range season.UncomparedFields {
+		if field == uncompared || strings.HasPrefix(field, uncompared+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the difference between the highest and lowest answer, returning false if any answer isn't a number
+func numericSpread(values []ScouterValue) (float64, bool) {
+	var numbers []float64
+	for _, value := range values {
+		number, ok := value.Value.(float64)
+		if !ok {
+			return 0, false
+		}
+		numbers = append(numbers, number)
+	}
+
+	return slices.Max(numbers) - slices.Min(numbers), true
+}
+
+// Builds the cell that flags a merged row on the sheet. Empty if the scouters agreed on everything.
+func disagreementFlag(report DisagreementReport) string {
+	if len(report.Disagreements) == 0 {
+		return ""
+	}
+
+	var fields []string
+	for _, disagreement := range report.Disagreements {
+		if disagreement.Spread != nil {
+			fields = append(fields, fmt.Sprintf("%v (off by %v)", disagreement.Field, *disagreement.Spread))
+		} else {
+			fields = append(fields, disagreement.Field)
+		}
+	}
+
+	return "CHECK: " + strings.Join(fields, "; ")
+}
+
+// Merges entries of the same match and driverstation by the rules of their season.
+// Returns the row to write, with a flag cell on the end, along with the disagreement report behind the flag.
+func mergeSlot(season *GameSeason, event string, entries []MatchEntry) ([]interface{}, DisagreementReport) {
+	report := BuildDisagreementReport(season, event, entries)
+	row := append(season.RawDataRow(entries...), disagreementFlag(report))
+	return row, report
+}
@@ -0,0 +1,575 @@
+package internal
+
+// A stand-in for the google sheets API, for running the whole server on a laptop with no network or google credentials.
+// It answers the sheets API calls the server makes from memory, so the real sheets client works against it unchanged.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Sheets backend enum
+const (
+	SheetsBackendGoogle = "google" // The real google sheets API
+	SheetsBackendFake   = "fake"   // The in-process stand-in
+)
+
+// The spreadsheet ID used with the fake backend if none is configured
+const kFakeSpreadsheetID = "fake"
+
+// Where the fake backend pretends to be
+const kFakeSheetsEndpoint = "http://fake-sheets.local/"
+
+// One tab of a fake spreadsheet
+type fakeTab struct {
+	ID                 int64             `json:"ID"`                 // The sheet ID google would have given it
+	Title              string            `json:"Title"`              // The name of the tab
+	Cells              [][]interface{}   `json:"Cells"`              // The cells, by row then column, starting from A1
+	ConditionalFormats []json.RawMessage `json:"ConditionalFormats"` // Every conditional format rule added to it, as sent
+}
+
+// One fake spreadsheet
+type fakeSpreadsheet struct {
+	ID     string     `json:"ID"`     // The spreadsheet ID
+	Title  string     `json:"Title"`  // The name of the spreadsheet
+	Tabs   []*fakeTab `json:"Tabs"`   // Every tab, in order
+	NextID int64      `json:"NextID"` // The sheet ID the next tab gets
+}
+
+// Every fake spreadsheet, optionally saved to a file after every change
+type fakeSheetsStore struct {
+	mutex        sync.Mutex
+	path         string
+	Spreadsheets map[string]*fakeSpreadsheet `json:"Spreadsheets"`
+}
+
+// Sets up the sheets API to use the fake backend, keeping its spreadsheets in the passed in file, or only in memory if it is empty.
+// The configured spreadsheet is created with the usual tabs if it doesn't exist yet.
+func SetupFakeSheetsAPI(path string, spreadsheetID string) {
+	store := &fakeSheetsStore{path: path, Spreadsheets: make(map[string]*fakeSpreadsheet)}
+
+	if path != "" {
+		data, readErr := os.ReadFile(path)
+		if readErr == nil {
+			if unmarshalErr := json.Unmarshal(data, store); unmarshalErr != nil {
+				FatalError(unmarshalErr, "Unable to read fake sheets from "+path)
+			}
+		} else if !os.IsNotExist(readErr) {
+			FatalError(readErr, "Unable to read fake sheets from "+path)
+		}
+	}
+
+	if spreadsheetID == "" {
+		spreadsheetID = kFakeSpreadsheetID
+	}
+	if _, ok := store.Spreadsheets[spreadsheetID]; !ok {
+		spreadsheet := store.create(spreadsheetID, "GreenScout")
+		for _, tab := range sheetTabs {
+			spreadsheet.addTab(tab)
+		}
+		store.save()
+	}
+
+	var err error
+	Srv, err = sheets.NewService(context.Background(),
+		option.WithEndpoint(kFakeSheetsEndpoint),
+		option.WithHTTPClient(&http.Client{Transport: store}),
+	)
+	if err != nil {
+		FatalError(err, "Unable to set up fake sheets")
+	}
+
+	SpreadsheetId = spreadsheetID
+	LogMessagef("Using fake sheets with spreadsheet %v (saved to %v)", spreadsheetID, path)
+}
+
+// Adds an empty spreadsheet
+func (store *fakeSheetsStore) create(id string, title string) *fakeSpreadsheet {
+	spreadsheet := &fakeSpreadsheet{ID: id, Title: title}
+	store.Spreadsheets[id] = spreadsheet
+	return spreadsheet
+}
+
+// Adds an empty tab to the end of the spreadsheet
+func (spreadsheet *fakeSpreadsheet) addTab(title string) *fakeTab {
+	tab := &fakeTab{ID: spreadsheet.NextID, Title: title}
+	spreadsheet.NextID++
+	spreadsheet.Tabs = append(spreadsheet.Tabs, tab)
+	return tab
+}
+
+// Gets a tab by its name
+func (spreadsheet *fakeSpreadsheet) tab(title string) (*fakeTab, bool) {
+	for _, tab := range spreadsheet.Tabs {
+		if tab.Title == title {
+			return tab, true
+		}
+	}
+	return nil, false
+}
+
+// Saves every spreadsheet to the store's file, if it has one
+func (store *fakeSheetsStore) save() {
+	if store.path == "" {
+		return
+	}
+
+	data, marshalErr := json.Marshal(store)
+	if marshalErr != nil {
+		LogError(marshalErr, "Problem encoding fake sheets")
+		return
+	}
+
+	if writeErr := os.WriteFile(store.path+".tmp", data, 0644); writeErr != nil {
+		LogErrorf(writeErr, "Problem saving fake sheets to %v", store.path)
+		return
+	}
+	if renameErr := os.Rename(store.path+".tmp", store.path); renameErr != nil {
+		LogErrorf(renameErr, "Problem saving fake sheets to %v", store.path)
+	}
+}
+
+// An error the way the sheets API sends them
+type fakeSheetsError struct {
+	code    int
+	message string
+}
+
+func (err fakeSheetsError) Error() string {
+	return err.message
+}
+
+// Answers a sheets API request from memory
+func (store *fakeSheetsStore) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var readErr error
+		body, readErr = io.ReadAll(request.Body)
+		request.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	store.mutex.Lock()
+	result, changed, err := store.handle(request.Method, request.URL, body)
+	if err == nil && changed {
+		store.save()
+	}
+	store.mutex.Unlock()
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadRequest
+		if sheetsErr, ok := err.(fakeSheetsError); ok {
+			status = sheetsErr.code
+		}
+		result = map[string]any{"error": map[string]any{"code": status, "message": err.Error()}}
+	}
+
+	responseBody, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%v %v", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(responseBody)),
+		Request:    request,
+	}, nil
+}
+
+// Routes a sheets API request, returning what to answer with and if anything changed
+func (store *fakeSheetsStore) handle(method string, requestURL *url.URL, body []byte) (any, bool, error) {
+	path := strings.TrimPrefix(requestURL.Path, "/v4/spreadsheets")
+
+	if path == "" && method == http.MethodPost {
+		return store.createSpreadsheet(body)
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	id, rest, _ := strings.Cut(path, "/")
+	id, action, _ := strings.Cut(id, ":")
+
+	spreadsheet, ok := store.Spreadsheets[id]
+	if !ok {
+		return nil, false, fakeSheetsError{http.StatusNotFound, "Requested entity was not found."}
+	}
+
+	switch {
+	case rest == "" && action == "" && method == http.MethodGet:
+		return spreadsheet.describe(), false, nil
+	case rest == "" && action == "batchUpdate":
+		return spreadsheet.batchUpdate(body)
+	case rest == "values:batchUpdate":
+		return spreadsheet.batchUpdateValues(body)
+	case rest == "values:batchClear":
+		return spreadsheet.batchClear(body)
+	case rest == "values:batchGet":
+		return spreadsheet.batchGet(requestURL.Query()["ranges"])
+	case strings.HasPrefix(rest, "values/"):
+		a1 := strings.TrimPrefix(rest, "values/")
+		switch {
+		case strings.HasSuffix(a1, ":append"):
+			return spreadsheet.appendValues(strings.TrimSuffix(a1, ":append"), body)
+		case strings.HasSuffix(a1, ":clear"):
+			return spreadsheet.batchClear(mustMarshal(map[string]any{"ranges": []string{strings.TrimSuffix(a1, ":clear")}}))
+		case method == http.MethodPut:
+			return spreadsheet.updateValues(a1, body)
+		case method == http.MethodGet:
+			values, err := spreadsheet.get(a1)
+			return values, false, err
+		}
+	}
+
+	return nil, false, fakeSheetsError{http.StatusNotImplemented, fmt.Sprintf("The fake sheets backend doesn't support %v %v", method, requestURL.Path)}
+}
+
+// Marshals something that can't fail to marshal
+func mustMarshal(value any) []byte {
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// A parsed A1 range. Rows and columns count from 0, and an end of -1 means it goes on forever.
+type fakeRange struct {
+	tab                string
+	startRow, startCol int
+	endRow, endCol     int
+}
+
+// Parses one end of an A1 range (B5, B, 5), returning -1 for whatever it leaves out
+func parseA1Cell(cell string) (int, int, error) {
+	letters := strings.TrimRightFunc(cell, func(r rune) bool { return r >= '0' && r <= '9' })
+	digits := cell[len(letters):]
+
+	col, row := -1, -1
+	if letters != "" {
+		col = 0
+		for _, letter := range strings.ToUpper(letters) {
+			if letter < 'A' || letter > 'Z' {
+				return 0, 0, fmt.Errorf("bad cell %v", cell)
+			}
+			col = col*26 + int(letter-'A') + 1
+		}
+		col--
+	}
+	if digits != "" {
+		parsed, err := strconv.Atoi(digits)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("bad cell %v", cell)
+		}
+		row = parsed - 1
+	}
+
+	return row, col, nil
+}
+
+// Parses an A1 range like RawData!B2:Z into the tab and the cells it covers
+func parseA1Range(a1 string) (fakeRange, error) {
+	tab, cells, found := strings.Cut(a1, "!")
+	if !found {
+		return fakeRange{tab: a1, endRow: -1, endCol: -1}, nil
+	}
+	tab = strings.Trim(tab, "'")
+
+	start, end, isRange := strings.Cut(cells, ":")
+	startRow, startCol, err := parseA1Cell(start)
+	if err != nil {
+		return fakeRange{}, err
+	}
+
+	parsed := fakeRange{tab: tab, startRow: max(startRow, 0), startCol: max(startCol, 0), endRow: -1, endCol: -1}
+	if !isRange {
+		if startRow != -1 && startCol != -1 { // A single cell is where writes start, and runs on from there
+			return parsed, nil
+		}
+		end = start
+	}
+
+	endRow, endCol, err := parseA1Cell(end)
+	if err != nil {
+		return fakeRange{}, err
+	}
+	parsed.endRow, parsed.endCol = endRow, endCol
+	return parsed, nil
+}
+
+// Gets the tab a range is on
+func (spreadsheet *fakeSpreadsheet) rangeTab(parsed fakeRange) (*fakeTab, error) {
+	tab, ok := spreadsheet.tab(parsed.tab)
+	if !ok {
+		return nil, fakeSheetsError{http.StatusBadRequest, "Unable to parse range: " + parsed.tab}
+	}
+	return tab, nil
+}
+
+// Writes rows of values starting at a cell, growing the tab as needed
+func (tab *fakeTab) write(row int, col int, values [][]interface{}) {
+	for i, rowValues := range values {
+		for len(tab.Cells) <= row+i {
+			tab.Cells = append(tab.Cells, []interface{}{})
+		}
+		cells := tab.Cells[row+i]
+		for len(cells) < col+len(rowValues) {
+			cells = append(cells, "")
+		}
+		copy(cells[col:], rowValues)
+		tab.Cells[row+i] = cells
+	}
+}
+
+// Returns if a cell has nothing in it
+func isEmptyCell(value interface{}) bool {
+	return value == nil || value == ""
+}
+
+// Gets the cells in a range the way the sheets API does, without trailing empty rows and columns
+func (tab *fakeTab) read(parsed fakeRange) [][]interface{} {
+	values := [][]interface{}{}
+	for row := parsed.startRow; row < len(tab.Cells) && (parsed.endRow == -1 || row <= parsed.endRow); row++ {
+		var rowValues []interface{}
+		for col := parsed.startCol; col < len(tab.Cells[row]) && (parsed.endCol == -1 || col <= parsed.endCol); col++ {
+			rowValues = append(rowValues, tab.Cells[row][col])
+		}
+		for len(rowValues) > 0 && isEmptyCell(rowValues[len(rowValues)-1]) {
+			rowValues = rowValues[:len(rowValues)-1]
+		}
+		values = append(values, rowValues)
+	}
+
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+// Answers a Spreadsheets.Get
+func (spreadsheet *fakeSpreadsheet) describe() map[string]any {
+	var tabs []map[string]any
+	for i, tab := range spreadsheet.Tabs {
+		tabs = append(tabs, map[string]any{
+			"properties":         map[string]any{"sheetId": tab.ID, "title": tab.Title, "index": i},
+			"conditionalFormats": tab.ConditionalFormats,
+		})
+	}
+
+	return map[string]any{
+		"spreadsheetId": spreadsheet.ID,
+		"properties":    map[string]any{"title": spreadsheet.Title},
+		"sheets":        tabs,
+	}
+}
+
+// Answers a Spreadsheets.Create
+func (store *fakeSheetsStore) createSpreadsheet(body []byte) (any, bool, error) {
+	var request sheets.Spreadsheet
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, err
+	}
+
+	title := "Untitled spreadsheet"
+	if request.Properties != nil && request.Properties.Title != "" {
+		title = request.Properties.Title
+	}
+
+	id := fmt.Sprintf("fake-%v", len(store.Spreadsheets)+1)
+	spreadsheet := store.create(id, title)
+
+	if len(request.Sheets) == 0 {
+		spreadsheet.addTab("Sheet1")
+	}
+	for _, tab := range request.Sheets {
+		if tab.Properties != nil {
+			spreadsheet.addTab(tab.Properties.Title)
+		}
+	}
+
+	return spreadsheet.describe(), true, nil
+}
+
+// Answers a Spreadsheets.BatchUpdate. Adding tabs and conditional formatting are supported, and anything else is accepted and ignored.
+func (spreadsheet *fakeSpreadsheet) batchUpdate(body []byte) (any, bool, error) {
+	var request struct {
+		Requests []map[string]json.RawMessage `json:"requests"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, err
+	}
+
+	var replies []map[string]any
+	for _, change := range request.Requests {
+		reply := map[string]any{}
+
+		if raw, ok := change["addSheet"]; ok {
+			var addSheet sheets.AddSheetRequest
+			if err := json.Unmarshal(raw, &addSheet); err != nil {
+				return nil, false, err
+			}
+			title := fmt.Sprintf("Sheet%v", len(spreadsheet.Tabs)+1)
+			if addSheet.Properties != nil && addSheet.Properties.Title != "" {
+				title = addSheet.Properties.Title
+			}
+			if _, exists := spreadsheet.tab(title); exists {
+				return nil, false, fakeSheetsError{http.StatusBadRequest, fmt.Sprintf("A sheet with the name \"%v\" already exists.", title)}
+			}
+			tab := spreadsheet.addTab(title)
+			reply["addSheet"] = map[string]any{"properties": map[string]any{"sheetId": tab.ID, "title": tab.Title, "index": len(spreadsheet.Tabs) - 1}}
+		}
+
+		if raw, ok := change["addConditionalFormatRule"]; ok {
+			var addRule sheets.AddConditionalFormatRuleRequest
+			if err := json.Unmarshal(raw, &addRule); err != nil {
+				return nil, false, err
+			}
+
+			var sheetID int64
+			if addRule.Rule != nil && len(addRule.Rule.Ranges) > 0 {
+				sheetID = addRule.Rule.Ranges[0].SheetId
+			}
+			for _, tab := range spreadsheet.Tabs {
+				if tab.ID == sheetID {
+					rule, _ := json.Marshal(addRule.Rule)
+					tab.ConditionalFormats = append(tab.ConditionalFormats, rule)
+				}
+			}
+		}
+
+		replies = append(replies, reply)
+	}
+
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "replies": replies}, true, nil
+}
+
+// Answers a Values.Update
+func (spreadsheet *fakeSpreadsheet) updateValues(a1 string, body []byte) (any, bool, error) {
+	var valueRange sheets.ValueRange
+	if err := json.Unmarshal(body, &valueRange); err != nil {
+		return nil, false, err
+	}
+
+	parsed, err := parseA1Range(a1)
+	if err != nil {
+		return nil, false, err
+	}
+	tab, err := spreadsheet.rangeTab(parsed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tab.write(parsed.startRow, parsed.startCol, valueRange.Values)
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "updatedRange": a1, "updatedRows": len(valueRange.Values)}, true, nil
+}
+
+// Answers a Values.BatchUpdate
+func (spreadsheet *fakeSpreadsheet) batchUpdateValues(body []byte) (any, bool, error) {
+	var request sheets.BatchUpdateValuesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, err
+	}
+
+	for _, valueRange := range request.Data {
+		if _, _, err := spreadsheet.updateValues(valueRange.Range, mustMarshal(valueRange)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "totalUpdatedRows": len(request.Data)}, true, nil
+}
+
+// Answers a Values.Append. The values go after the last row with anything in it from the range's first column on.
+func (spreadsheet *fakeSpreadsheet) appendValues(a1 string, body []byte) (any, bool, error) {
+	var valueRange sheets.ValueRange
+	if err := json.Unmarshal(body, &valueRange); err != nil {
+		return nil, false, err
+	}
+
+	parsed, err := parseA1Range(a1)
+	if err != nil {
+		return nil, false, err
+	}
+	tab, err := spreadsheet.rangeTab(parsed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	row := parsed.startRow
+	for i := len(tab.Cells) - 1; i >= parsed.startRow; i-- {
+		if len(tab.read(fakeRange{startRow: i, endRow: i, startCol: parsed.startCol, endCol: -1})) > 0 {
+			row = i + 1
+			break
+		}
+	}
+
+	tab.write(row, parsed.startCol, valueRange.Values)
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "tableRange": a1}, true, nil
+}
+
+// Answers a Values.BatchClear, which is also how single clears are done
+func (spreadsheet *fakeSpreadsheet) batchClear(body []byte) (any, bool, error) {
+	var request sheets.BatchClearValuesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, err
+	}
+
+	for _, a1 := range request.Ranges {
+		parsed, err := parseA1Range(a1)
+		if err != nil {
+			return nil, false, err
+		}
+		tab, err := spreadsheet.rangeTab(parsed)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for row := parsed.startRow; row < len(tab.Cells) && (parsed.endRow == -1 || row <= parsed.endRow); row++ {
+			for col := parsed.startCol; col < len(tab.Cells[row]) && (parsed.endCol == -1 || col <= parsed.endCol); col++ {
+				tab.Cells[row][col] = ""
+			}
+		}
+	}
+
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "clearedRanges": request.Ranges}, true, nil
+}
+
+// Answers a Values.Get
+func (spreadsheet *fakeSpreadsheet) get(a1 string) (map[string]any, error) {
+	parsed, err := parseA1Range(a1)
+	if err != nil {
+		return nil, err
+	}
+	tab, err := spreadsheet.rangeTab(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"range": a1, "majorDimension": "ROWS", "values": tab.read(parsed)}, nil
+}
+
+// Answers a Values.BatchGet
+func (spreadsheet *fakeSpreadsheet) batchGet(ranges []string) (any, bool, error) {
+	var valueRanges []map[string]any
+	for _, a1 := range ranges {
+		valueRange, err := spreadsheet.get(a1)
+		if err != nil {
+			return nil, false, err
+		}
+		valueRanges = append(valueRanges, valueRange)
+	}
+
+	return map[string]any{"spreadsheetId": spreadsheet.ID, "valueRanges": valueRanges}, false, nil
+}
@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseA1Range(t *testing.T) {
+	tests := []struct {
+		a1   string
+		want fakeRange
+	}{
+		{"RawData", fakeRange{tab: "RawData", endRow: -1, endCol: -1}},
+		{"RawData!B2:Z", fakeRange{tab: "RawData", startRow: 1, startCol: 1, endRow: -1, endCol: 25}},
+		{"'Pit Scouting'!A1:C3", fakeRange{tab: "Pit Scouting", endRow: 2, endCol: 2}},
+		{"RawData!B5", fakeRange{tab: "RawData", startRow: 4, startCol: 1, endRow: -1, endCol: -1}},
+		{"RawData!A:A", fakeRange{tab: "RawData", endRow: -1, endCol: 0}},
+		{"RawData!2:2", fakeRange{tab: "RawData", startRow: 1, endRow: 1, endCol: -1}},
+		{"RawData!AA10", fakeRange{tab: "RawData", startRow: 9, startCol: 26, endRow: -1, endCol: -1}},
+	}
+	for _, test := range tests {
+		got, err := parseA1Range(test.a1)
+		if err != nil {
+			t.Errorf("%v: %v", test.a1, err)
+		} else if got != test.want {
+			t.Errorf("%v parsed to %+v, want %+v", test.a1, got, test.want)
+		}
+	}
+
+	for _, bad := range []string{"RawData!B0", "RawData!1B", "RawData!B2:?"} {
+		if _, err := parseA1Range(bad); err == nil {
+			t.Errorf("%v parsed", bad)
+		}
+	}
+}
+
+// Reads a range of the fake spreadsheet as text
+func readTestRange(t *testing.T, a1 string) [][]string {
+	t.Helper()
+
+	response, err := Srv.Spreadsheets.Values.Get(SpreadsheetId, a1).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows [][]string
+	for _, row := range response.Values {
+		rows = append(rows, cellTexts(row))
+	}
+	return rows
+}
+
+func TestFakeAppendGoesAfterLastRow(t *testing.T) {
+	setupTestSheets(t)
+
+	if _, err := Srv.Spreadsheets.Values.Update(SpreadsheetId, "RawData!A2", &sheets.ValueRange{Values: [][]interface{}{
+		{"1", "a"}, {"1", "b"}, {"1"}, {"2"},
+	}}).ValueInputOption("RAW").Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Srv.Spreadsheets.Values.Append(SpreadsheetId, "RawData!B2", &sheets.ValueRange{Values: [][]interface{}{{"c"}}}).
+		ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"1", "a"}, {"1", "b"}, {"1", "c"}, {"2"}}
+	if got := readTestRange(t, "RawData!A2:B"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("after appending, RawData reads %v, want %v (column A shouldn't count as data)", got, want)
+	}
+
+	if _, err := Srv.Spreadsheets.Values.Append(SpreadsheetId, "PitScouting!B2", &sheets.ValueRange{Values: [][]interface{}{{"first"}}}).
+		ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestRange(t, "PitScouting!B2:B"); !slices.EqualFunc(got, [][]string{{"first"}}, slices.Equal) {
+		t.Errorf("append to an empty tab reads %v, want it at the start of the range", got)
+	}
+}
+
+func TestFakeBatchClearKeepsHeadersAndMatchNumbers(t *testing.T) {
+	setupTestSheets(t)
+
+	if _, err := Srv.Spreadsheets.Values.Update(SpreadsheetId, "RawData!A1", &sheets.ValueRange{Values: [][]interface{}{
+		{"Match", "Team"}, {"1", "1816"}, {"1", "2052"},
+	}}).ValueInputOption("RAW").Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Srv.Spreadsheets.Values.BatchClear(SpreadsheetId, &sheets.BatchClearValuesRequest{Ranges: []string{"RawData!B2:Z"}}).Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"Match", "Team"}, {"1"}, {"1"}}
+	if got := readTestRange(t, "RawData!A1:Z"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("after clearing, RawData reads %v, want %v", got, want)
+	}
+
+	if _, err := Srv.Spreadsheets.Values.BatchClear(SpreadsheetId, &sheets.BatchClearValuesRequest{Ranges: []string{"Nope!B2:Z"}}).Do(); err == nil {
+		t.Error("cleared a tab that doesn't exist")
+	}
+}
+
+// Gets the conditional format rules on every tab of the fake spreadsheet, by tab name
+func testConditionalFormats(t *testing.T) map[string][]*sheets.ConditionalFormatRule {
+	t.Helper()
+
+	spreadsheet, err := Srv.Spreadsheets.Get(SpreadsheetId).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := make(map[string][]*sheets.ConditionalFormatRule)
+	for _, tab := range spreadsheet.Sheets {
+		rules[tab.Properties.Title] = tab.ConditionalFormats
+	}
+	return rules
+}
+
+func TestFakeConditionalFormatRuleGoesOnItsTab(t *testing.T) {
+	setupTestSheets(t)
+
+	spreadsheet, err := Srv.Spreadsheets.Get(SpreadsheetId).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prescoutingID int64 = -1
+	for _, tab := range spreadsheet.Sheets {
+		if tab.Properties.Title == TabPrescouting {
+			prescoutingID = tab.Properties.SheetId
+		}
+	}
+	if prescoutingID <= 0 {
+		t.Fatalf("Prescouting has sheet ID %v", prescoutingID)
+	}
+
+	rule := &sheets.ConditionalFormatRule{
+		BooleanRule: &sheets.BooleanRule{Condition: &sheets.BooleanCondition{Type: "NOT_BLANK"}},
+		Ranges:      []*sheets.GridRange{{SheetId: prescoutingID, StartRowIndex: 1}},
+	}
+	if _, err := Srv.Spreadsheets.BatchUpdate(SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{
+		{AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{Rule: rule}},
+	}}).Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := testConditionalFormats(t)
+	if len(rules[TabPrescouting]) != 1 || rules[TabPrescouting][0].BooleanRule.Condition.Type != "NOT_BLANK" {
+		t.Errorf("Prescouting has rules %+v, want the one added", rules[TabPrescouting])
+	}
+	if len(rules[TabRawData]) != 0 {
+		t.Errorf("RawData has rules %+v, want none", rules[TabRawData])
+	}
+}
+
+func TestWriteConditionalFormatting(t *testing.T) {
+	setupTestSheets(t)
+
+	WriteConditionalFormatting()
+
+	rules := testConditionalFormats(t)
+	if len(rules[TabRawData]) != 2 {
+		t.Fatalf("RawData has %v rules, want the red and the blue one", len(rules[TabRawData]))
+	}
+	for _, rule := range rules[TabRawData] {
+		if rule.BooleanRule.Condition.Type != "CUSTOM_FORMULA" || rule.Ranges[0].EndColumnIndex != 1 {
+			t.Errorf("RawData has rule %+v, want a formula over column A", rule.BooleanRule.Condition)
+		}
+	}
+	if len(rules[TabPitScouting]) != 0 {
+		t.Errorf("PitScouting has rules %+v, want none", rules[TabPitScouting])
+	}
+}
+
+func TestFillMatches(t *testing.T) {
+	setupTestSheets(t)
+
+	FillMatches(2, 3)
+
+	var want [][]string
+	for _, match := range []string{"2", "3"} {
+		for range 6 {
+			want = append(want, []string{match})
+		}
+	}
+	if got := readTestRange(t, "RawData!A8:A19"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("RawData column A reads %v, want six rows of each match", got)
+	}
+	if got := readTestRange(t, "RawData!A2:A7"); len(got) != 0 {
+		t.Errorf("rows of match 1 read %v, want them left alone", got)
+	}
+}
@@ -2,6 +2,7 @@
 package internal
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"strings"
@@ -44,3 +45,13 @@ func IsSudo() bool {
 	out, _ := exec.Command("whoami").Output()
 	return strings.Contains(string(out), "root")
 }
+
+// Reads an entire file, returning nil data and no error if it does not exist.
+// Similar to os.ReadFile().
+func readFileIfExists(filepath string) ([]byte, error) {
+	data, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -2,39 +2,36 @@ package internal
 
 import (
 	"fmt"
-	"github.com/montanaflynn/stats"
+	"strings"
 )
 
-// Utility for merging multiple MatchData instances into data to be written to the spreadsheet when multi-scouting
+// Utility for merging multiple 2026 TeamData instances into data to be written to the spreadsheet when multi-scouting.
+// Other seasons keep their merge rules in their own game_YEAR.go
 
 // Compliled data for an entire match from multiple scouters
-type MultiMatch struct { // TODO: FIX FOR NEW
-	TeamNumber    uint64             `json:"Team"`  // The team number
-	Match         MatchInfo          `json:"Match"` // The match number
-	Scouters      string             // The scouters who scouted this entry
-	DriverStation DriverStationData  `json:"Driver Station"` // The driverstation of this entry
-	CycleData     CompositeCycleData // The compiled cycle data from multiple scouters
-	// Pickups       PickupLocations    // The compiled pickup locations from multiple scouters
-	Auto   AutoData // The compiled auto data from multiple scouters
-	Parked bool     // If any scouter recorded a park
-	Notes  []string // The compiled notes from multiple scouters
-}
-
-// Compiled scouting data from multiple scouters
-type CompositeCycleData struct {
-	NumCycles     int     // The computed number of cycles
-	AvgCycleTime  float64 // The average cycle time
-	AllCycles     []Cycle // All cycles raw
-	HadMismatches bool    // If there were any mismatches
+type MultiMatch struct {
+	TeamNumber    uint64            `json:"Team"`  // The team number
+	Match         MatchInfo         `json:"Match"` // The match number
+	Scouters      string            // The scouters who scouted this entry
+	DriverStation DriverStationData `json:"Driver Station"` // The driverstation of this entry
+	Auto          AutoData          // The compiled auto data from multiple scouters
+	Teleop        TeleopData        // The compiled teleop data from multiple scouters
+	Endgame       EndgameData       // The compiled endgame data from multiple scouters
+	Issues        IssuesData        // Every issue any scouter recorded
+	Notes         []string          // The compiled notes from multiple scouters, followed by anything they disagreed on
 }
 
 // Compiles Teamdata entries into one MultiMatch
-func CompileMultiMatch(entries ...TeamData) MultiMatch { // TODO: FIX FOR NEW
+func CompileMultiMatch(entries ...TeamData) MultiMatch {
 	var finalData MultiMatch
+	var mismatches []string
 
 	// guy who uses c#: heh system.linq could this in 1/3 of the code
 
-	teamNum, _ := compositeTeamNum(entries)
+	teamNum, teamMismatch := compositeTeamNum(entries)
+	if teamMismatch {
+		mismatches = append(mismatches, "TEAM NUMBER MISMATCH")
+	}
 
 	finalData.TeamNumber = uint64(teamNum)
 
@@ -44,17 +41,25 @@ func CompileMultiMatch(entries ...TeamData) MultiMatch { // TODO: FIX FOR NEW
 
 	finalData.DriverStation = entries[0].DriverStation
 
-	// finalData.CycleData = compileCycles(entries)
+	var scouters []string
+	for _, entry := range entries {
+		scouters = append(scouters, entry.Scouter)
+	}
+	merger := newFieldMerger(scouters)
 
-	// finalData.Pickups = compilePickupPositions(entries)
+	finalData.Auto = compileAutoData(merger, entries)
 
-	finalData.Auto = compileAutoData(entries)
+	teleop, teleopMismatches := compileTeleopData(merger, entries)
+	finalData.Teleop = teleop
+	mismatches = append(mismatches, teleopMismatches...)
 
-	//TODO: DO MULTISCOUTING ENDGAME -Leon
+	endgame, endgameMismatches := compileEndgameData(merger, entries)
+	finalData.Endgame = endgame
+	mismatches = append(mismatches, endgameMismatches...)
 
-	// finalData.Parked = compileParked(entries)
+	finalData.Issues = compileIssues(merger, entries)
 
-	finalData.Notes = compileNotes(entries, nil)
+	finalData.Notes = compileNotes(entries, mismatches)
 
 	return finalData
 }
@@ -82,240 +87,141 @@ func compositeScouters(entries []TeamData) string {
 	return finalScouter
 }
 
-// Compiles the cycle data from all matches into one CompositeCycleData
-//
-//	func compileCycles(entries []TeamData) CompositeCycleData {
-//		var finalCycles CompositeCycleData
-//		var allNumCycles []int
-//		for _, entry := range entries {
-//			allNumCycles = append(allNumCycles, GetNumCycles(entry.Cycles))
-//		}
-//
-//		for _, cycleNum := range allNumCycles {
-//			if cycleNum != allNumCycles[0] {
-//				finalCycles.HadMismatches = true
-//			}
-//		}
-//
-//		cycleCompositeTime, hadMismatches := avgCycleTimes(entries)
-//
-//		finalCycles.AvgCycleTime = cycleCompositeTime
-//
-//		if hadMismatches {
-//			finalCycles.HadMismatches = true
-//		}
-//
-//		var massiveBlockOfCycles []Cycle
-//		for _, entry := range entries {
-//			massiveBlockOfCycles = append(massiveBlockOfCycles, entry.Cycles...)
-//		}
-//
-//		finalCycles.AllCycles = massiveBlockOfCycles
-//
-//		return finalCycles
-//	}
-//
-// Averages out the cycle times from all entries, returning this average as well as if there were any times that were outside
-// of the configured acceptable range
-// func avgCycleTimes(entries []TeamData) (float64, bool) {
-// 	var sum float64
-// 	var count int = 0
-//
-// 	var allCycles [][]Cycle
-//
-// 	for _, entry := range entries {
-// 		allCycles = append(allCycles, entry.Cycles)
-// 		entryAvg := GetAvgCycleTimeExclusive(entry.Cycles)
-// 		if entryAvg != 0 {
-// 			sum += entryAvg
-// 			count++
-// 		}
-// 	}
-//
-// 	finalAvg := sum / float64(count)
-//
-// 	if math.IsNaN(finalAvg) {
-// 		finalAvg = 0
-// 	}
-// 	return finalAvg, !CompareCycles(allCycles)
-// }
-
-// Combines the pickup locations from all entries
-// func compilePickupPositions(entries []TeamData) PickupLocations {
-// 	var cGround bool = false
-// 	var cSource bool = false
-// 	var ground bool = false
-// 	var source bool = false
-
-// 	for _, entry := range entries {
-// 		if entry.Pickups.AlgaeGround {
-// 			ground = true
-// 		}
-
-// 		if entry.Pickups.AlgaeSource {
-// 			source = true
-// 		}
-
-// 		if entry.Pickups.CoralGround {
-// 			cGround = true
-// 		}
-
-// 		if entry.Pickups.CoralSource {
-// 			cSource = true
-// 		}
-// 	}
-
-// 	return PickupLocations{
-// 		CoralGround: cGround,
-// 		CoralSource: cSource,
-// 		AlgaeGround: ground,
-// 		AlgaeSource: source,
-// 	}
-// }
-
 // Compiles autonomous data from all entries
-func compileAutoData(entries []TeamData) AutoData {
-	// No need to mess with return values if err, as the NaNs do that well enough.
-
-	var can bool = false
-	var hang bool = false
-	var won bool = false
-
-	var left bool = false
-	var right bool = false
-	var mid bool = false
-	var top bool = false
-	var bump bool = false
-	var trench bool = false
-	var didntCross bool = false
-	var hp bool = false
-	var fuel bool = false
-
-	var allScores []float64
-	var allMisses []float64
-	var allEjects []float64
-	var allHumanAccuracy []float64
-	var allRobotAccuracy []float64
-
-	for _, entry := range entries {
-		if entry.Auto.CanAuto {
-			can = true
-		}
-		if entry.Auto.HangAuto {
-			hang = true
-		}
-		if entry.Auto.WonAuto {
-			won = true
-		}
+func compileAutoData(merger *fieldMerger, entries []TeamData) AutoData {
+	return AutoData{
+		CanAuto:  merger.Bool("auto.canAuto", collectBools(entries, func(entry TeamData) bool { return entry.Auto.CanAuto }), MergeAny),
+		HangAuto: merger.Bool("auto.hangAuto", collectBools(entries, func(entry TeamData) bool { return entry.Auto.HangAuto }), MergeAny),
+		Scores:   int(merger.Number("auto.scores", collectInts(entries, func(entry TeamData) int { return entry.Auto.Scores }), MergeMean)),
+		Misses:   int(merger.Number("auto.misses", collectInts(entries, func(entry TeamData) int { return entry.Auto.Misses }), MergeMean)),
+		Ejects:   int(merger.Number("auto.ejects", collectInts(entries, func(entry TeamData) int { return entry.Auto.Ejects }), MergeMean)),
+		WonAuto:  merger.Bool("auto.won", collectBools(entries, func(entry TeamData) bool { return entry.Auto.WonAuto }), MergeAny),
 
+		Accuracy: AutoAccuracy{
+			HPAccuracy:    int(merger.Number("auto.accuracy.hpAccuracy", collectInts(entries, func(entry TeamData) int { return entry.Auto.Accuracy.HPAccuracy }), MergeMean)),
+			RobotAccuracy: int(merger.Number("auto.accuracy.robotAccuracy", collectInts(entries, func(entry TeamData) int { return entry.Auto.Accuracy.RobotAccuracy }), MergeMean)),
+		},
 		// Field booleans: if any scouter marked it, keep it.
-		if entry.Auto.Field.Left {
-			left = true
-		}
-		if entry.Auto.Field.Right {
-			right = true
-		}
-		if entry.Auto.Field.Mid {
-			mid = true
-		}
-		if entry.Auto.Field.Top {
-			top = true
-		}
-		if entry.Auto.Field.Bump {
-			bump = true
-		}
-		if entry.Auto.Field.Trench {
-			trench = true
-		}
-		if entry.Auto.Field.DidntCross {
-			didntCross = true
-		}
-		if entry.Auto.Field.HP {
-			hp = true
-		}
-		if entry.Auto.Field.Fuel {
-			fuel = true
-		}
+		Field: AutoField{
+			Left:       merger.Bool("auto.field.left", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Left }), MergeAny),
+			Right:      merger.Bool("auto.field.right", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Right }), MergeAny),
+			Mid:        merger.Bool("auto.field.mid", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Mid }), MergeAny),
+			Top:        merger.Bool("auto.field.top", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Top }), MergeAny),
+			Bump:       merger.Bool("auto.field.bump", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Bump }), MergeAny),
+			Trench:     merger.Bool("auto.field.trench", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Trench }), MergeAny),
+			DidntCross: merger.Bool("auto.field.didntCross", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.DidntCross }), MergeAny),
+			HP:         merger.Bool("auto.field.hp", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.HP }), MergeAny),
+			Fuel:       merger.Bool("auto.field.fuel", collectBools(entries, func(entry TeamData) bool { return entry.Auto.Field.Fuel }), MergeAny),
+		},
+	}
+}
 
-		allScores = append(allScores, float64(entry.Auto.Scores))
-		allMisses = append(allMisses, float64(entry.Auto.Misses))
-		allEjects = append(allEjects, float64(entry.Auto.Ejects))
+// Compiles teleop data from all entries, along with anything the scouters disagreed on
+func compileTeleopData(merger *fieldMerger, entries []TeamData) (TeleopData, []string) {
+	var teleop TeleopData
+	var mismatches []string
 
-		allHumanAccuracy = append(allHumanAccuracy, float64(entry.Auto.Accuracy.HPAccuracy))
-		allRobotAccuracy = append(allRobotAccuracy, float64(entry.Auto.Accuracy.RobotAccuracy))
-	}
+	teleop.Collection.CollectNeutral = merger.Bool("teleop.collection.collectNeutral", collectBools(entries, func(entry TeamData) bool { return entry.Teleop.Collection.CollectNeutral }), MergeAny)
+	teleop.Collection.CollectHP = merger.Bool("teleop.collection.collectHp", collectBools(entries, func(entry TeamData) bool { return entry.Teleop.Collection.CollectHP }), MergeAny)
+	teleop.Field.Bump = merger.Bool("teleop.field.bump", collectBools(entries, func(entry TeamData) bool { return entry.Teleop.Field.Bump }), MergeAny)
+	teleop.Field.Trench = merger.Bool("teleop.field.trench", collectBools(entries, func(entry TeamData) bool { return entry.Teleop.Field.Trench }), MergeAny)
 
-	scoresAvgd, scoresMeanErr := stats.Mean(allScores)
-	if scoresMeanErr != nil {
-		LogErrorf(scoresMeanErr, "Error finding mean of %v for all scores", allScores)
-	}
+	capacities := collectStrings(entries, func(entry TeamData) string { return entry.Teleop.Collection.FuelCapacity })
+	botTypes := collectStrings(entries, func(entry TeamData) string { return entry.Teleop.BotType })
+	playstyles := collectStrings(entries, func(entry TeamData) string { return entry.Teleop.Playstyle })
 
-	missesAvgd, missesMeanErr := stats.Mean(allMisses)
-	if missesMeanErr != nil {
-		LogErrorf(missesMeanErr, "Error finding mean of %v for all misses", allMisses)
-	}
+	var capacityMismatch, botTypeMismatch, playstyleMismatch bool
+	teleop.Collection.FuelCapacity, capacityMismatch = merger.String("teleop.collection.fuelCapacity", capacities, MergeMode)
+	teleop.BotType, botTypeMismatch = merger.String("teleop.botType", botTypes, MergeMode)
+	teleop.Playstyle, playstyleMismatch = merger.String("teleop.playstyle", playstyles, MergeMode)
 
-	ejectsAvgd, ejectsMeanErr := stats.Mean(allEjects)
-	if ejectsMeanErr != nil {
-		LogErrorf(ejectsMeanErr, "Error finding mean of %v for all ejects", allEjects)
+	if capacityMismatch {
+		mismatches = append(mismatches, "FUEL CAPACITY MISMATCH: "+strings.Join(capacities, "/"))
+	}
+	if botTypeMismatch {
+		mismatches = append(mismatches, "BOT TYPE MISMATCH: "+strings.Join(botTypes, "/"))
+	}
+	if playstyleMismatch {
+		mismatches = append(mismatches, "PLAYSTYLE MISMATCH: "+strings.Join(playstyles, "/"))
 	}
 
-	hpAccAvgd, hpAccMeanErr := stats.Mean(allHumanAccuracy)
-	if hpAccMeanErr != nil {
-		LogErrorf(hpAccMeanErr, "Error finding mean of %v for all HP accuracy", allHumanAccuracy)
+	return teleop, mismatches
+}
+
+// Compiles endgame data from all entries, along with anything the scouters disagreed on
+func compileEndgameData(merger *fieldMerger, entries []TeamData) (EndgameData, []string) {
+	var endgame EndgameData
+	var mismatches []string
+
+	endgame.EndgameShoot = merger.Bool("endgame.endgameShoot", collectBools(entries, func(entry TeamData) bool { return entry.Endgame.EndgameShoot }), MergeAny)
+
+	// A climb time of 0 means the scouter didn't time a climb, so by default it doesn't drag the average down
+	climbTimes := collectFloats(entries, func(entry TeamData) float64 { return entry.Endgame.ClimbTimer })
+	endgame.ClimbTimer = merger.Number("endgame.climbTimer", climbTimes, MergeNonzeroMean)
+
+	parks := collectStrings(entries, func(entry TeamData) string { return entry.Endgame.Park })
+	park, parkMismatch := merger.String("endgame.park", parks, MergeMode)
+	endgame.Park = park
+	if parkMismatch {
+		mismatches = append(mismatches, "PARK MISMATCH: "+strings.Join(parks, "/"))
 	}
 
-	robotAccAvgd, robotAccMeanErr := stats.Mean(allRobotAccuracy)
-	if robotAccMeanErr != nil {
-		LogErrorf(robotAccMeanErr, "Error finding mean of %v for all robot accuracy", allRobotAccuracy)
+	return endgame, mismatches
+}
+
+// Compiles the issues from all entries. By default, if any scouter saw an issue, it happened.
+func compileIssues(merger *fieldMerger, entries []TeamData) IssuesData {
+	return IssuesData{
+		Disconnect:  merger.Bool("issues.disconnect", collectBools(entries, func(entry TeamData) bool { return entry.Issues.Disconnect }), MergeAny),
+		LoseTrack:   merger.Bool("issues.loseTrack", collectBools(entries, func(entry TeamData) bool { return entry.Issues.LoseTrack }), MergeAny),
+		EverBeached: merger.Bool("issues.everBeached", collectBools(entries, func(entry TeamData) bool { return entry.Issues.EverBeached }), MergeAny),
 	}
+}
 
-	return AutoData{
-		CanAuto:  can,
-		HangAuto: hang,
-		Scores:   int(scoresAvgd),
-		Misses:   int(missesAvgd),
-		Ejects:   int(ejectsAvgd),
-		WonAuto:  won,
+// Collects one integer field from every entry, ready to be merged
+func collectInts(entries []TeamData, get func(entry TeamData) int) []float64 {
+	var values []float64
+	for _, entry := range entries {
+		values = append(values, float64(get(entry)))
+	}
+	return values
+}
 
-		Accuracy: AutoAccuracy{
-			HPAccuracy:    int(hpAccAvgd),
-			RobotAccuracy: int(robotAccAvgd),
-		},
-		Field: AutoField{
-			Left:       left,
-			Right:      right,
-			Mid:        mid,
-			Top:        top,
-			Bump:       bump,
-			Trench:     trench,
-			DidntCross: didntCross,
-			HP:         hp,
-			Fuel:       fuel,
-		},
+// Collects one decimal field from every entry, ready to be merged
+func collectFloats(entries []TeamData, get func(entry TeamData) float64) []float64 {
+	var values []float64
+	for _, entry := range entries {
+		values = append(values, get(entry))
 	}
+	return values
 }
 
-//TODO: ENDGAME compile MULTI -Leon
+// Collects one boolean field from every entry, ready to be merged
+func collectBools(entries []TeamData, get func(entry TeamData) bool) []bool {
+	var values []bool
+	for _, entry := range entries {
+		values = append(values, get(entry))
+	}
+	return values
+}
 
-// Returns if any scouter recorded a park
-// func compileParked(entries []TeamData) bool {
-// 	for _, entry := range entries {
-// 		if entry.Endgame.ParkStatus > 3 {
-// 			return true
-// 		}
-// 	}
-// 	return false
-// }
+// Collects one text field from every entry, ready to be merged
+func collectStrings(entries []TeamData, get func(entry TeamData) string) []string {
+	var values []string
+	for _, entry := range entries {
+		values = append(values, get(entry))
+	}
+	return values
+}
 
-// Combines the notes from all passed in scouters
+// Combines the notes from all passed in scouters, followed by anything they disagreed on
 func compileNotes(entries []TeamData, mismatches []string) []string {
 	var finalNotes []string
 	for _, entry := range entries {
 		combined := fmt.Sprintf("%s; %s; %s; %s; %s", entry.Notes.Auto, entry.Notes.Teleop, entry.Notes.Perf, entry.Notes.Events, entry.Notes.Comments)
 
 		finalNotes = append(finalNotes, combined)
-		finalNotes = append(finalNotes, mismatches...)
 	}
+	finalNotes = append(finalNotes, mismatches...)
 	return finalNotes
 }
@@ -0,0 +1,596 @@
+package internal
+
+// The 2025 game, REEFSCAPE. Kept around so its data still parses, and because its pit scouting form is still in use.
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+)
+
+func init() {
+	RegisterSeason(&GameSeason{
+		Year: 2025,
+		Name: "REEFSCAPE",
+
+		NewMatchEntry: func() MatchEntry { return &ReefscapeTeamData{} },
+		NewPitEntry:   func() PitEntry { return &PitScoutingData{} },
+
+		ValidateMatch: validateReefscape,
+
+		MatchColumns: []string{
+			"Driver Station", "Match", "Team", "Avg Cycle Time", "Cycles", "Cycle Accuracy", "Pickups",
+			"Had Auto", "Auto Scores", "Auto Accuracy", "Auto Ejects", "Parked", "Climb Time", "Notes",
+		},
+		MatchRow: func(entry MatchEntry) []interface{} {
+			return reefscapeRow(*entry.(*ReefscapeTeamData))
+		},
+		MergedRow: func(entries []MatchEntry) []interface{} {
+			var teams []ReefscapeTeamData
+			for _, entry := range entries {
+				teams = append(teams, *entry.(*ReefscapeTeamData))
+			}
+			return reefscapeMergedRow(teams)
+		},
+		PrescoutRow: func(entry MatchEntry) []interface{} {
+			return reefscapePrescoutRow(*entry.(*ReefscapeTeamData))
+		},
+		PrescoutCols: []string{
+			"Driver Station", "Team", "Avg Cycle Time", "Cycles", "Had Auto", "Auto Scores", "Auto Accuracy", "Auto Ejects", "Climb Time", "Notes",
+		},
+		PitColumns: reefscapePitColumns,
+		PitRow:     reefscapePitRow,
+
+		MatchFormatters: reefscapeFormatters,
+		CompileRow:      compileReefscapeRow,
+		PitFormatters:   reefscapePitFormatters,
+
+		Tables:        reefscapeTables,
+		StorePitEntry: storeReefscapePit,
+
+		UncomparedFields: []string{"Scouter", "Match", "Driver Station", "Notes", "Rescouting", "Prescouting"},
+	})
+}
+
+// A 2025 match scouting entry, as sent by the frontend that season
+type ReefscapeTeamData struct {
+	TeamNumber    uint64                 `json:"Team"`
+	Match         MatchInfo              `json:"Match"`
+	Scouter       string                 `json:"Scouter"`
+	DriverStation ReefscapeDriverStation `json:"Driver Station"`
+
+	Cycles  []Cycle          `json:"Cycles"`
+	Pickups PickupLocations  `json:"Pickup Locations"`
+	Auto    ReefscapeAuto    `json:"Auto"`
+	Endgame ReefscapeEndgame `json:"Endgame"`
+	Misc    ReefscapeMisc    `json:"Misc"`
+
+	Penalties   []string `json:"Penalties"`
+	Rescouting  bool     `json:"Rescouting"`
+	Prescouting bool     `json:"Prescouting"`
+	Notes       string   `json:"Notes"`
+}
+
+// The driverstation, as laid out in 2025
+type ReefscapeDriverStation struct {
+	IsBlue bool `json:"Is Blue"` // If it is blue
+	Number int  `json:"Number"`  // The driverstation number (1-3)
+}
+
+// Where a robot picked up game pieces from
+type PickupLocations struct {
+	CoralGround bool `json:"Coral Ground"`
+	CoralSource bool `json:"Coral Source"`
+	AlgaeGround bool `json:"Algae Ground"`
+	AlgaeSource bool `json:"Algae Source"`
+}
+
+// What a robot did during auto in 2025
+type ReefscapeAuto struct {
+	Can    bool `json:"Can"`    // If it had an auto
+	Scores int  `json:"Scores"` // How many pieces it scored
+	Misses int  `json:"Misses"` // How many pieces it missed
+	Ejects int  `json:"Ejects"` // How many pieces it shuttled
+}
+
+// What a robot did during endgame in 2025
+type ReefscapeEndgame struct {
+	ParkStatus int     `json:"Parking Status"` // How it ended the match, anything over 3 counts as parked
+	Time       float64 `json:"Time"`           // How long climbing took
+}
+
+// Problems during the match
+type ReefscapeMisc struct {
+	Disconnect bool `json:"Lost Communication or Disabled"`
+	LostTrack  bool `json:"User Lost Track"`
+}
+
+// The parts of the entry every season shares
+func (team *ReefscapeTeamData) Info() EntryInfo {
+	return EntryInfo{
+		TeamNumber:    team.TeamNumber,
+		Match:         team.Match,
+		Scouter:       team.Scouter,
+		DriverStation: DriverStationData{IsBlue: team.DriverStation.IsBlue, Number: team.DriverStation.Number},
+		Rescouting:    team.Rescouting,
+		Prescouting:   team.Prescouting,
+	}
+}
+
+// Overwrites who the entry says scouted it
+func (team *ReefscapeTeamData) SetScouter(scouter string) {
+	team.Scouter = scouter
+}
+
+// Checks the counters and cycles of a 2025 entry
+func validateReefscape(entry MatchEntry) []FieldError {
+	team := entry.(*ReefscapeTeamData)
+
+	var errs []FieldError
+	errs = checkCounter(errs, "Auto.Scores", team.Auto.Scores)
+	errs = checkCounter(errs, "Auto.Misses", team.Auto.Misses)
+	errs = checkCounter(errs, "Auto.Ejects", team.Auto.Ejects)
+	errs = checkCounter(errs, "Cycles", len(team.Cycles))
+	for i, cycle := range team.Cycles {
+		errs = checkTimer(errs, fmt.Sprintf("Cycles.%v.Time", i), cycle.Time)
+	}
+	errs = checkTimer(errs, "Endgame.Time", team.Endgame.Time)
+
+	return errs
+}
+
+// Gets the accuracy of a robot during auto, returning N/A if 0 attempts were made
+func reefscapeAutoAccuracy(auto ReefscapeAuto) any {
+	attempts := auto.Scores + auto.Misses
+
+	if attempts == 0 {
+		return "N/A"
+	}
+	return (float64(auto.Scores) / float64(attempts)) * 100
+}
+
+// Turns pickup locations into something readable on the sheet
+func reefscapePickupString(pickups PickupLocations) string {
+	var out string
+
+	if pickups.CoralGround {
+		out += "Coral Ground; "
+	}
+	if pickups.CoralSource {
+		out += "Coral Source; "
+	}
+	if pickups.AlgaeGround {
+		out += "Algae Ground; "
+	}
+	if pickups.AlgaeSource {
+		out += "Algae Source; "
+	}
+
+	return out
+}
+
+// Compiles losing track, DCs, penalties and notes into one string
+func reefscapeNotes(entries []ReefscapeTeamData) string {
+	var finalNote string = ""
+	var lostTrack bool = false
+	var DC bool = false
+	var notes []string
+
+	for _, entry := range entries {
+		if entry.Misc.LostTrack {
+			lostTrack = true
+		}
+		if entry.Misc.Disconnect {
+			DC = true
+		}
+
+		for _, penalty := range entry.Penalties {
+			if penalty != "" {
+				notes = append(notes, penalty)
+			}
+		}
+		notes = append(notes, entry.Notes)
+	}
+
+	if lostTrack {
+		finalNote += "LOST TRACK; "
+	}
+
+	if DC {
+		finalNote += "DISCONNECTED; "
+	}
+
+	finalNote += strings.Join(notes, "; ")
+	return finalNote
+}
+
+// Builds the RawData row of a single 2025 entry
+func reefscapeRow(team ReefscapeTeamData) []interface{} {
+	// This is ONE ROW. Each value is a cell in that row.
+	return []interface{}{
+		GetDSString(team.DriverStation.IsBlue, uint(team.DriverStation.Number)),
+		team.Match.Number,
+		team.TeamNumber,
+		GetAvgCycleTime(team.Cycles),  // Avg cycle time
+		GetNumCycles(team.Cycles),     // Num Cycles
+		GetCycleAccuracy(team.Cycles), // Cycle accuracy
+		reefscapePickupString(team.Pickups),
+		team.Auto.Can,                             // Had Auto
+		team.Auto.Scores,                          // Scores in auto
+		reefscapeAutoAccuracy(team.Auto),          // Auto accuracy
+		team.Auto.Ejects,                          // Auto shuttles
+		team.Endgame.ParkStatus > 3,               // Parked
+		team.Endgame.Time,                         // Climb Time
+		reefscapeNotes([]ReefscapeTeamData{team}), // Notes + Penalties + DC + Lost track
+	}
+}
+
+// Several 2025 entries of the same match and driverstation, combined
+type reefscapeMerged struct {
+	Cycles    CompositeCycleData
+	Pickups   PickupLocations
+	Auto      ReefscapeAuto
+	Parked    bool
+	ClimbTime float64
+	Notes     string
+}
+
+// Combines several 2025 entries of the same match and driverstation, following the configured merge strategies
+func mergeReefscape(entries []ReefscapeTeamData) reefscapeMerged {
+	cycles := compileReefscapeCycles(entries)
+
+	var scouters []string
+	var cans, parks, coralGround, coralSource, algaeGround, algaeSource []bool
+	var allScores, allMisses, allEjects, climbTimes []float64
+
+	for _, entry := range entries {
+		scouters = append(scouters, entry.Scouter)
+
+		cans = append(cans, entry.Auto.Can)
+		parks = append(parks, entry.Endgame.ParkStatus > 3)
+
+		allScores = append(allScores, float64(entry.Auto.Scores))
+		allMisses = append(allMisses, float64(entry.Auto.Misses))
+		allEjects = append(allEjects, float64(entry.Auto.Ejects))
+		climbTimes = append(climbTimes, entry.Endgame.Time)
+
+		coralGround = append(coralGround, entry.Pickups.CoralGround)
+		coralSource = append(coralSource, entry.Pickups.CoralSource)
+		algaeGround = append(algaeGround, entry.Pickups.AlgaeGround)
+		algaeSource = append(algaeSource, entry.Pickups.AlgaeSource)
+	}
+
+	merger := newFieldMerger(scouters)
+
+	pickups := PickupLocations{
+		CoralGround: merger.Bool("Pickup Locations.Coral Ground", coralGround, MergeAny),
+		CoralSource: merger.Bool("Pickup Locations.Coral Source", coralSource, MergeAny),
+		AlgaeGround: merger.Bool("Pickup Locations.Algae Ground", algaeGround, MergeAny),
+		AlgaeSource: merger.Bool("Pickup Locations.Algae Source", algaeSource, MergeAny),
+	}
+
+	auto := ReefscapeAuto{
+		Can:    merger.Bool("Auto.Can", cans, MergeAny),
+		Scores: int(merger.Number("Auto.Scores", allScores, MergeMean)),
+		Misses: int(merger.Number("Auto.Misses", allMisses, MergeMean)),
+		Ejects: int(merger.Number("Auto.Ejects", allEjects, MergeMean)),
+	}
+
+	parked := merger.Bool("Endgame.Parking Status", parks, MergeAny)
+	climbTime := merger.Number("Endgame.Time", climbTimes, MergeNonzeroMean) // Robots that didn't climb are left at 0
+
+	notes := reefscapeNotes(entries)
+	if cycles.HadMismatches {
+		notes = "CYCLE MISMATCH; " + notes
+	}
+
+	return reefscapeMerged{
+		Cycles:    cycles,
+		Pickups:   pickups,
+		Auto:      auto,
+		Parked:    parked,
+		ClimbTime: climbTime,
+		Notes:     notes,
+	}
+}
+
+// Builds the RawData row of several merged 2025 entries
+func reefscapeMergedRow(entries []ReefscapeTeamData) []interface{} {
+	merged := mergeReefscape(entries)
+
+	return []interface{}{
+		GetDSString(entries[0].DriverStation.IsBlue, uint(entries[0].DriverStation.Number)),
+		entries[0].Match.Number,
+		entries[0].TeamNumber,
+		merged.Cycles.AvgCycleTime,
+		merged.Cycles.NumCycles,
+		GetCycleAccuracy(merged.Cycles.AllCycles),
+		reefscapePickupString(merged.Pickups),
+		merged.Auto.Can,
+		merged.Auto.Scores,
+		reefscapeAutoAccuracy(merged.Auto),
+		merged.Auto.Ejects,
+		merged.Parked,
+		merged.ClimbTime,
+		merged.Notes,
+	}
+}
+
+// Merges the entries behind a 2025 row once for every cell of it. Rows of a single entry don't need merging, so they get nil.
+func compileReefscapeRow(entries []MatchEntry) any {
+	if len(entries) == 1 {
+		return nil
+	}
+
+	var teams []ReefscapeTeamData
+	for _, entry := range entries {
+		teams = append(teams, *entry.(*ReefscapeTeamData))
+	}
+	return mergeReefscape(teams)
+}
+
+// Builds a formatter out of how a cell is worked out for one 2025 entry and for several merged ones
+func reefscapeCell(single func(team ReefscapeTeamData) interface{}, merged func(merged reefscapeMerged) interface{}) MatchFormatter {
+	return func(entries []MatchEntry, compiled any) interface{} {
+		if len(entries) == 1 {
+			return single(*entries[0].(*ReefscapeTeamData))
+		}
+		return merged(compiled.(reefscapeMerged))
+	}
+}
+
+// The cells sheet layouts can use for 2025 entries, named after what builds them
+var reefscapeFormatters = map[string]MatchFormatter{
+	"GetAvgCycleTime": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return GetAvgCycleTime(team.Cycles) },
+		func(merged reefscapeMerged) interface{} { return merged.Cycles.AvgCycleTime },
+	),
+	"GetNumCycles": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return GetNumCycles(team.Cycles) },
+		func(merged reefscapeMerged) interface{} { return merged.Cycles.NumCycles },
+	),
+	"GetCycleAccuracy": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return GetCycleAccuracy(team.Cycles) },
+		func(merged reefscapeMerged) interface{} { return GetCycleAccuracy(merged.Cycles.AllCycles) },
+	),
+	"Pickups": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return reefscapePickupString(team.Pickups) },
+		func(merged reefscapeMerged) interface{} { return reefscapePickupString(merged.Pickups) },
+	),
+	"GetAutoAccuracy": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return reefscapeAutoAccuracy(team.Auto) },
+		func(merged reefscapeMerged) interface{} { return reefscapeAutoAccuracy(merged.Auto) },
+	),
+	"Parked": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return team.Endgame.ParkStatus > 3 },
+		func(merged reefscapeMerged) interface{} { return merged.Parked },
+	),
+	"CompileNotes": reefscapeCell(
+		func(team ReefscapeTeamData) interface{} { return reefscapeNotes([]ReefscapeTeamData{team}) },
+		func(merged reefscapeMerged) interface{} { return merged.Notes },
+	),
+}
+
+// Builds the Prescouting row of a prescouted 2025 entry
+func reefscapePrescoutRow(team ReefscapeTeamData) []interface{} {
+	// This is ONE ROW. Each value is a cell in that row.
+	return []interface{}{
+		GetDSString(team.DriverStation.IsBlue, uint(team.DriverStation.Number)),
+		team.TeamNumber,                           // Team Number
+		GetAvgCycleTime(team.Cycles),              // Avg cycle time
+		GetNumCycles(team.Cycles),                 // Num Cycles
+		team.Auto.Can,                             // Had Auto
+		team.Auto.Scores,                          // Scores in auto
+		reefscapeAutoAccuracy(team.Auto),          // Auto accuracy
+		team.Auto.Ejects,                          // Auto shuttles
+		team.Endgame.Time,                         // Climb Time
+		reefscapeNotes([]ReefscapeTeamData{team}), // Notes + Penalties + DC + Lost track
+	}
+}
+
+// Compiled scouting data from multiple scouters
+type CompositeCycleData struct {
+	NumCycles     int     // The computed number of cycles
+	AvgCycleTime  float64 // The average cycle time
+	AllCycles     []Cycle // All cycles raw
+	HadMismatches bool    // If there were any mismatches
+}
+
+// Compiles the cycle data from all entries into one CompositeCycleData
+func compileReefscapeCycles(entries []ReefscapeTeamData) CompositeCycleData {
+	var finalCycles CompositeCycleData
+	var allNumCycles []int
+	for _, entry := range entries {
+		allNumCycles = append(allNumCycles, GetNumCycles(entry.Cycles))
+	}
+
+	for _, cycleNum := range allNumCycles {
+		if cycleNum != allNumCycles[0] {
+			finalCycles.HadMismatches = true
+		}
+	}
+	finalCycles.NumCycles = allNumCycles[0]
+
+	cycleCompositeTime, hadMismatches := avgReefscapeCycleTimes(entries)
+
+	finalCycles.AvgCycleTime = cycleCompositeTime
+
+	if hadMismatches {
+		finalCycles.HadMismatches = true
+	}
+
+	var massiveBlockOfCycles []Cycle
+	for _, entry := range entries {
+		massiveBlockOfCycles = append(massiveBlockOfCycles, entry.Cycles...)
+	}
+
+	finalCycles.AllCycles = massiveBlockOfCycles
+
+	return finalCycles
+}
+
+// Averages out the cycle times from all entries, returning this average as well as if there were any times that were outside
+// of the configured acceptable range
+func avgReefscapeCycleTimes(entries []ReefscapeTeamData) (float64, bool) {
+	var sum float64
+	var count int = 0
+
+	var allCycles [][]Cycle
+
+	for _, entry := range entries {
+		allCycles = append(allCycles, entry.Cycles)
+		entryAvg := GetAvgCycleTimeExclusive(entry.Cycles)
+		if entryAvg != 0 {
+			sum += entryAvg
+			count++
+		}
+	}
+
+	finalAvg := sum / float64(count)
+
+	if math.IsNaN(finalAvg) {
+		finalAvg = 0
+	}
+	return finalAvg, !CompareCycles(allCycles)
+}
+
+// Data from pit scouting, on the 2025 form
+type PitScoutingData struct {
+	TeamNumber int `json:"Team"` // The team number
+	// PitIdentifier string `json:"Pit"`     // The pit identifier, as seen on the pit map
+	Scouter string `json:"Scouter"` // The person who did the pit scouting
+	Notes   string `json:"Notes"`   // Other notes
+
+	Weight  string `json:"Weight"`          //The Weight of the robot
+	AutoNum string `json:"Number of Autos"` //Number of Autos
+	Dynamic bool   `json:"Dyanamic Auto?"`  //Whether or not the team has dynamic autos
+
+	Drivetrain          string    `json:"Drive Train"`                                   // The type of drivetrain the robot has
+	GearRatio           string    `json:"Gear Ratio"`                                    //  The type of gearratio the robot has
+	Coral               CoralData `json:"Coral Position"`                                //The position of the coral on the reef
+	Algae               AlgaeData `json:"Algae Position"`                                //The position of the algae on the reef
+	AlgaeGround         bool      `json:"Algae Ground Pickup"`                           //Whether the team is able to pick up from the ground
+	AlgaeSource         bool      `json:"Algae Source Pickup"`                           //Whether the team is able to pick up from the source
+	Cycle               int       `json:"Driver Years of Experience"`                    //How long the driver has been driving
+	Experience          string    `json:"Cycle Time"`                                    //The team's average cycle time
+	Teleop              int       `json:"Preferred Teleop"`                              //The preferred teleop???
+	Endgame             int       `json:"Preferred Endgame"`                             //The preferred endgame
+	Shallow             bool      `json:"Can Climb Shallow Cage"`                        //Whether it used the shallow climb
+	Deep                bool      `json:"Can Climb Deep Cage"`                           //Whether it used the deep climb
+	RobotTypeCompliment string    `json:"What Type of Robot Would Compliment You Best?"` //Question for pit scouting
+	FavoritePart        string    `json:"Favorite Part of the Robot?"`                   //Question for pit scouting                               //Notes for other relevant information
+}
+
+// The levels of the reef a robot can score coral on
+type CoralData struct {
+	L1 bool `json:"L1"` //L1 position of the reef
+	L2 bool `json:"L2"` //L2 position of the reef
+	L3 bool `json:"L3"` //L3 position of the reef
+	L4 bool `json:"L4"` //L4 position of the reef
+}
+
+// The levels of the reef a robot can remove algae from
+type AlgaeData struct {
+	L2 bool `json:"A1"` //A1 position of the reef
+	L3 bool `json:"A2"` //A2 position of the reef
+}
+
+// The team that was pit scouted
+func (pit *PitScoutingData) Team() int {
+	return pit.TeamNumber
+}
+
+// The person who did the pit scouting
+func (pit *PitScoutingData) ScoutedBy() string {
+	return pit.Scouter
+}
+
+// The headers of the PitScouting tab for the 2025 form
+var reefscapePitColumns = []string{
+	"Team", "Scouter", "Weight", "Autos", "Dynamic Auto", "Drivetrain", "Gear Ratio", "Coral", "Algae",
+	"Algae Ground", "Algae Source", "Driver Experience", "Preferred Teleop", "Preferred Endgame",
+	"Shallow Climb", "Deep Climb", "Compliment", "Favorite Part", "Notes",
+}
+
+// Builds the PitScouting row of a Reefscape pit entry
+func reefscapePitRow(pit PitEntry) []interface{} {
+	pitData := pit.(*PitScoutingData)
+
+	// This is ONE ROW. Each value is a cell in that row.
+	valuesToWrite := []interface{}{
+		pitData.TeamNumber,  //Team Number
+		pitData.Scouter,     //Person/people who pit scouted
+		pitData.Weight,      //The weight of the robot
+		pitData.AutoNum,     //The number of autos they have
+		pitData.Dynamic,     //Whether they have dynamic autos
+		pitData.Drivetrain,  //The type of drivetrain
+		pitData.GearRatio,   //The GearRatio on the top of my head
+		pitData.Coral,       //The position(s) their robot is able to score
+		pitData.Algae,       //The position(s) their robot is able to score
+		pitData.AlgaeGround, //Whether it can collect algae from the ground
+		pitData.AlgaeSource, //Whether it can collect algae from the source
+		// pitData.Cycle,               //Their cycle time
+		pitData.Experience,          //The driver's experience
+		pitData.Teleop,              //The strategy for teleop??
+		pitData.Endgame,             //The strategy for endgame
+		pitData.Shallow,             //Whether it can shallow climb
+		pitData.Deep,                //Whether it can deep climb
+		pitData.RobotTypeCompliment, //What part of the robot compliments you?
+		pitData.FavoritePart,        //Favortite part of the robot
+		pitData.Notes,               //Other Notes
+
+	}
+
+	return valuesToWrite
+}
+
+// Lists the levels of the reef that are checked off, like "L2, L4"
+func reefLevelString(levels map[string]bool, order []string) string {
+	var out []string
+	for _, level := range order {
+		if levels[level] {
+			out = append(out, level)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// The cells sheet layouts can use on the PitScouting tab for the 2025 form
+var reefscapePitFormatters = map[string]PitFormatter{
+	"Coral": func(pit PitEntry) interface{} {
+		coral := pit.(*PitScoutingData).Coral
+		return reefLevelString(map[string]bool{"L1": coral.L1, "L2": coral.L2, "L3": coral.L3, "L4": coral.L4}, []string{"L1", "L2", "L3", "L4"})
+	},
+	"Algae": func(pit PitEntry) interface{} {
+		algae := pit.(*PitScoutingData).Algae
+		return reefLevelString(map[string]bool{"A1": algae.L2, "A2": algae.L3}, []string{"A1", "A2"})
+	},
+}
+
+// The tables 2025 entries are broken out into
+var reefscapeTables = []string{
+	`create table if not exists reefscape2025_pit(
+		entry int primary key references pit(id),
+		notes text, weight text, autonum text, dynamic int,
+		drivetrain text, gearratio text,
+		coral1 int, coral2 int, coral3 int, coral4 int, algae2 int, algae3 int,
+		algaeground int, algaesource int,
+		driverexperience int, cycletime text, preferredteleop int, preferredendgame int,
+		shallow int, deep int,
+		compliment text, favoritepart text
+	)`,
+}
+
+// Stores the answers of the 2025 pit scouting form into their table
+func storeReefscapePit(tx *sql.Tx, id int64, entry PitEntry) error {
+	pit := entry.(*PitScoutingData)
+
+	_, err := tx.Exec(
+		"insert or replace into reefscape2025_pit values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, pit.Notes, pit.Weight, pit.AutoNum, pit.Dynamic, pit.Drivetrain, pit.GearRatio,
+		pit.Coral.L1, pit.Coral.L2, pit.Coral.L3, pit.Coral.L4, pit.Algae.L2, pit.Algae.L3, pit.AlgaeGround, pit.AlgaeSource,
+		pit.Cycle, pit.Experience, pit.Teleop, pit.Endgame, pit.Shallow, pit.Deep, pit.RobotTypeCompliment, pit.FavoritePart,
+	)
+	return err
+}
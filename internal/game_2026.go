@@ -0,0 +1,387 @@
+package internal
+
+// The 2026 game, REBUILT
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	RegisterSeason(&GameSeason{
+		Year: 2026,
+		Name: "REBUILT",
+
+		NewMatchEntry: func() MatchEntry { return &TeamData{} },
+		NewPitEntry:   func() PitEntry { return &PitScoutingData{} }, // Pit scouting still uses the 2025 form
+
+		ValidateMatch: validate2026,
+
+		MatchColumns: []string{
+			"Driver Station", "Match", "Team", "Collection", "Auto Field", "Had Auto", "Hang Auto", "Won Auto", "Auto Scores",
+			"Auto Accuracy", "HP Accuracy", "Robot Accuracy", "Auto Ejects", "Climb Time", "Park", "Style", "Notes",
+		},
+		MatchRow: func(entry MatchEntry) []interface{} {
+			return teamDataRow(*entry.(*TeamData))
+		},
+		MergedRow: func(entries []MatchEntry) []interface{} {
+			return multiMatchRow(CompileMultiMatch(teamDataOf(entries)...))
+		},
+		PrescoutRow: func(entry MatchEntry) []interface{} {
+			return prescoutDataRow(*entry.(*TeamData))
+		},
+		PrescoutCols: []string{
+			"Driver Station", "Team", "Had Auto", "Auto Scores", "Auto Accuracy", "Auto Ejects", "Climb Time", "Notes",
+		},
+		PitColumns: reefscapePitColumns,
+		PitRow:     reefscapePitRow,
+
+		MatchFormatters: formatters2026,
+		CompileRow:      compile2026Row,
+		PitFormatters:   reefscapePitFormatters,
+
+		Tables:        tables2026,
+		StoreMatch:    store2026Match,
+		StorePitEntry: storeReefscapePit, // Into the 2025 season's pit table
+
+		UncomparedFields: []string{"scouter", "submissionId", "match", "driverStation", "notes", "rescouting", "prescouting"},
+	})
+}
+
+// A 2026 match scouting entry, as sent by the frontend
+type TeamData struct {
+	TeamNumber    uint64            `json:"team"`
+	Match         MatchInfo         `json:"match"`
+	Scouter       string            `json:"scouter"`
+	DriverStation DriverStationData `json:"driverStation"`
+
+	Auto    AutoData    `json:"auto"`
+	Teleop  TeleopData  `json:"teleop"`
+	Endgame EndgameData `json:"endgame"`
+	Issues  IssuesData  `json:"issues"`
+	Notes   NotesData   `json:"notes"`
+
+	Rescouting  bool `json:"rescouting"`
+	Prescouting bool `json:"prescouting"`
+
+	SubmissionID string `json:"submissionId,omitempty"` // A UUID generated by the client, so retried uploads aren't stored twice
+}
+
+type AutoData struct {
+	CanAuto  bool `json:"canAuto"`
+	HangAuto bool `json:"hangAuto"`
+	Scores   int  `json:"scores"`
+	Misses   int  `json:"misses"`
+	Ejects   int  `json:"ejects"`
+	WonAuto  bool `json:"won"`
+
+	Accuracy AutoAccuracy `json:"accuracy"`
+	Field    AutoField    `json:"field"`
+}
+
+type AutoAccuracy struct {
+	HPAccuracy    int `json:"hpAccuracy"`
+	RobotAccuracy int `json:"robotAccuracy"`
+}
+
+type AutoField struct {
+	Left       bool `json:"left"`
+	Right      bool `json:"right"`
+	Mid        bool `json:"mid"`
+	Top        bool `json:"top"`
+	Bump       bool `json:"bump"`
+	Trench     bool `json:"trench"`
+	DidntCross bool `json:"didntCross"`
+	HP         bool `json:"hp"`
+	Fuel       bool `json:"fuel"`
+}
+
+type TeleopData struct {
+	Collection CollectionData `json:"collection"`
+	Field      TeleField      `json:"field"`
+	BotType    string         `json:"botType"`
+	Playstyle  string         `json:"playstyle"`
+}
+
+type CollectionData struct {
+	CollectNeutral bool   `json:"collectNeutral"`
+	CollectHP      bool   `json:"collectHp"`
+	FuelCapacity   string `json:"fuelCapacity"`
+}
+
+type TeleField struct {
+	Bump   bool `json:"bump"`
+	Trench bool `json:"trench"`
+}
+
+type EndgameData struct {
+	Park         string  `json:"park"`
+	ClimbTimer   float64 `json:"climbTimer"`
+	EndgameShoot bool    `json:"endgameShoot"`
+}
+
+type IssuesData struct {
+	Disconnect  bool `json:"disconnect"`
+	LoseTrack   bool `json:"loseTrack"`
+	EverBeached bool `json:"everBeached"`
+}
+
+type NotesData struct {
+	Perf     string `json:"perfNotes"`
+	Events   string `json:"eventsNotes"`
+	Comments string `json:"commentsNotes"`
+	Teleop   string `json:"teleNotes"`
+	Auto     string `json:"autoNotes"`
+}
+
+// The parts of the entry every season shares
+func (team *TeamData) Info() EntryInfo {
+	return EntryInfo{
+		TeamNumber:    team.TeamNumber,
+		Match:         team.Match,
+		Scouter:       team.Scouter,
+		DriverStation: team.DriverStation,
+		Rescouting:    team.Rescouting,
+		Prescouting:   team.Prescouting,
+		SubmissionID:  team.SubmissionID,
+	}
+}
+
+// Overwrites who the entry says scouted it
+func (team *TeamData) SetScouter(scouter string) {
+	team.Scouter = scouter
+}
+
+// Unwraps entries that are known to be 2026 ones
+func teamDataOf(entries []MatchEntry) []TeamData {
+	var teams []TeamData
+	for _, entry := range entries {
+		teams = append(teams, *entry.(*TeamData))
+	}
+	return teams
+}
+
+// Checks the counters and percentages of a 2026 entry
+func validate2026(entry MatchEntry) []FieldError {
+	team := entry.(*TeamData)
+
+	var errs []FieldError
+	errs = checkCounter(errs, "auto.scores", team.Auto.Scores)
+	errs = checkCounter(errs, "auto.misses", team.Auto.Misses)
+	errs = checkCounter(errs, "auto.ejects", team.Auto.Ejects)
+	errs = checkPercentage(errs, "auto.accuracy.hpAccuracy", team.Auto.Accuracy.HPAccuracy)
+	errs = checkPercentage(errs, "auto.accuracy.robotAccuracy", team.Auto.Accuracy.RobotAccuracy)
+	errs = checkTimer(errs, "endgame.climbTimer", team.Endgame.ClimbTimer)
+
+	return errs
+}
+
+// Builds the RawData row of a single 2026 entry
+func teamDataRow(teamData TeamData) []interface{} {
+	// This is ONE ROW. Each value is a cell in that row.
+	valuesToWrite := []interface{}{
+		GetDSString(teamData.DriverStation.IsBlue, uint(teamData.DriverStation.Number)),
+		teamData.Match.Number, // Match Number
+		teamData.TeamNumber,   // Team Number
+		// GetAvgCycleTime(teamData.Cycles), // Avg cycle time
+		// GetNumCycles(teamData.Cycles),    // Num Cycles
+		GetCollection(teamData.Teleop.Collection),
+		TurnAutoFieldIntoAnAwesomeAndReadableString(teamData.Auto.Field),
+		teamData.Auto.CanAuto,                                     // Had Auto
+		teamData.Auto.HangAuto,                                    // Had Hanging Auto
+		teamData.Auto.WonAuto,                                     // Won Auto
+		teamData.Auto.Scores,                                      // Scores in auto
+		GetAutoAccuracy(teamData.Auto),                            // Auto accuracy
+		fmt.Sprintf("%v%%", teamData.Auto.Accuracy.HPAccuracy),    // Accuracy of Human
+		fmt.Sprintf("%v%%", teamData.Auto.Accuracy.RobotAccuracy), // Accuracy of Robot
+		teamData.Auto.Ejects,                                      // Auto shuttles
+		teamData.Endgame.ClimbTimer,                               // Climb Time
+		teamData.Endgame.Park,                                     // Parked
+		GetStyleString(teamData.Teleop),
+		CompileNotes(teamData), // Notes + Penalties + DC + Lost track
+	}
+
+	return valuesToWrite
+}
+
+// Builds the RawData row of several merged 2026 entries. Has the same columns as teamDataRow.
+func multiMatchRow(matchdata MultiMatch) []interface{} {
+	// This is ONE ROW. Each value is a cell in that row.
+	valuesToWrite := []interface{}{
+		GetDSString(matchdata.DriverStation.IsBlue, uint(matchdata.DriverStation.Number)),
+		matchdata.Match.Number, // Match Number
+		matchdata.TeamNumber,   // Team Number
+		GetCollection(matchdata.Teleop.Collection),
+		TurnAutoFieldIntoAnAwesomeAndReadableString(matchdata.Auto.Field),
+		matchdata.Auto.CanAuto,                                     // Had Auto
+		matchdata.Auto.HangAuto,                                    // Had Hanging Auto
+		matchdata.Auto.WonAuto,                                     // Won Auto
+		matchdata.Auto.Scores,                                      // Scores in auto
+		GetAutoAccuracy(matchdata.Auto),                            // Auto accuracy
+		fmt.Sprintf("%v%%", matchdata.Auto.Accuracy.HPAccuracy),    // Accuracy of Human
+		fmt.Sprintf("%v%%", matchdata.Auto.Accuracy.RobotAccuracy), // Accuracy of Robot
+		matchdata.Auto.Ejects,                                      // Auto shuttles
+		matchdata.Endgame.ClimbTimer,                               // Climb Time
+		matchdata.Endgame.Park,                                     // Parked
+		GetStyleString(matchdata.Teleop),
+		CompileNotes2(matchdata), // Notes + Penalties + DC + Lost track
+	}
+
+	return valuesToWrite
+}
+
+// Builds the Prescouting row of a prescouted 2026 entry
+func prescoutDataRow(teamData TeamData) []interface{} {
+	// This is ONE ROW. Each value is a cell in that row.
+	valuesToWrite := []interface{}{
+		GetDSString(teamData.DriverStation.IsBlue, uint(teamData.DriverStation.Number)),
+		teamData.TeamNumber, // Team Number
+		// GetAvgCycleTime(teamData.Cycles), // Avg cycle time
+		// GetNumCycles(teamData.Cycles), // Num Cycles
+
+		teamData.Auto.CanAuto,          // Had Auto
+		teamData.Auto.Scores,           // Scores in auto
+		GetAutoAccuracy(teamData.Auto), // Auto accuracy
+		teamData.Auto.Ejects,           // Auto shuttles
+		teamData.Endgame.ClimbTimer,    // Climb Time
+		// GetParkStatus(teamData.Endgame),           // Parked
+		CompileNotes(teamData), // Notes + Penalties + DC + Lost track
+	}
+
+	return valuesToWrite
+}
+
+// The entries behind a 2026 row, merged once for every cell of it
+type compiledRow2026 struct {
+	team   TeamData    // The entry, or every entry merged into one
+	merged *MultiMatch // The merge, if there's more than one entry
+}
+
+// Combines the entries behind a row into one 2026 entry, merging them if there's more than one
+func compile2026Row(entries []MatchEntry) any {
+	teams := teamDataOf(entries)
+	if len(teams) == 1 {
+		return compiledRow2026{team: teams[0]}
+	}
+
+	merged := CompileMultiMatch(teams...)
+	return compiledRow2026{
+		team: TeamData{
+			TeamNumber:    merged.TeamNumber,
+			Match:         merged.Match,
+			Scouter:       merged.Scouters,
+			DriverStation: merged.DriverStation,
+			Auto:          merged.Auto,
+			Teleop:        merged.Teleop,
+			Endgame:       merged.Endgame,
+			Issues:        merged.Issues,
+		},
+		merged: &merged,
+	}
+}
+
+// Builds a formatter out of how a cell is worked out from a compiled 2026 row
+func cell2026(cell func(row compiledRow2026) interface{}) MatchFormatter {
+	return func(_ []MatchEntry, compiled any) interface{} {
+		return cell(compiled.(compiledRow2026))
+	}
+}
+
+// The cells sheet layouts can use for 2026 entries, named after what builds them
+var formatters2026 = map[string]MatchFormatter{
+	"GetCollection": cell2026(func(row compiledRow2026) interface{} {
+		return GetCollection(row.team.Teleop.Collection)
+	}),
+	"TurnAutoFieldIntoAnAwesomeAndReadableString": cell2026(func(row compiledRow2026) interface{} {
+		return TurnAutoFieldIntoAnAwesomeAndReadableString(row.team.Auto.Field)
+	}),
+	"GetAutoAccuracy": cell2026(func(row compiledRow2026) interface{} {
+		return GetAutoAccuracy(row.team.Auto)
+	}),
+	"HPAccuracy": cell2026(func(row compiledRow2026) interface{} {
+		return fmt.Sprintf("%v%%", row.team.Auto.Accuracy.HPAccuracy)
+	}),
+	"RobotAccuracy": cell2026(func(row compiledRow2026) interface{} {
+		return fmt.Sprintf("%v%%", row.team.Auto.Accuracy.RobotAccuracy)
+	}),
+	"GetTeleopCoverage": cell2026(func(row compiledRow2026) interface{} {
+		return GetTeleopCoverage(row.team.Teleop.Field)
+	}),
+	"GetStyleString": cell2026(func(row compiledRow2026) interface{} {
+		return GetStyleString(row.team.Teleop)
+	}),
+	"CompileNotes": cell2026(func(row compiledRow2026) interface{} {
+		if row.merged == nil {
+			return CompileNotes(row.team)
+		}
+		return CompileNotes2(*row.merged)
+	}),
+}
+
+// The tables 2026 entries are broken out into. Every section of TeamData gets its own table keyed by the entry it belongs to.
+var tables2026 = []string{
+	`create table if not exists rebuilt2026_auto(
+		entry int primary key references entries(id),
+		canauto int, hangauto int, scores int, misses int, ejects int, won int,
+		hpaccuracy int, robotaccuracy int,
+		fieldleft int, fieldright int, fieldmid int, fieldtop int, fieldbump int, fieldtrench int, fielddidntcross int, fieldhp int, fieldfuel int
+	)`,
+	`create table if not exists rebuilt2026_teleop(
+		entry int primary key references entries(id),
+		collectneutral int, collecthp int, fuelcapacity text,
+		fieldbump int, fieldtrench int,
+		bottype text, playstyle text
+	)`,
+	`create table if not exists rebuilt2026_endgame(
+		entry int primary key references entries(id),
+		park text, climbtimer real, endgameshoot int
+	)`,
+	`create table if not exists rebuilt2026_issues(
+		entry int primary key references entries(id),
+		disconnect int, losetrack int, everbeached int
+	)`,
+	`create table if not exists rebuilt2026_notes(
+		entry int primary key references entries(id),
+		perf text, events text, comments text, teleop text, auto text
+	)`,
+}
+
+// Stores the sections of a 2026 entry into their tables
+func store2026Match(tx *sql.Tx, id int64, entry MatchEntry) error {
+	team := entry.(*TeamData)
+
+	auto := team.Auto
+	if _, err := tx.Exec("insert or replace into rebuilt2026_auto values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, auto.CanAuto, auto.HangAuto, auto.Scores, auto.Misses, auto.Ejects, auto.WonAuto,
+		auto.Accuracy.HPAccuracy, auto.Accuracy.RobotAccuracy,
+		auto.Field.Left, auto.Field.Right, auto.Field.Mid, auto.Field.Top, auto.Field.Bump, auto.Field.Trench,
+		auto.Field.DidntCross, auto.Field.HP, auto.Field.Fuel,
+	); err != nil {
+		return err
+	}
+
+	tele := team.Teleop
+	if _, err := tx.Exec("insert or replace into rebuilt2026_teleop values(?, ?, ?, ?, ?, ?, ?, ?)",
+		id, tele.Collection.CollectNeutral, tele.Collection.CollectHP, tele.Collection.FuelCapacity,
+		tele.Field.Bump, tele.Field.Trench, tele.BotType, tele.Playstyle,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("insert or replace into rebuilt2026_endgame values(?, ?, ?, ?)",
+		id, team.Endgame.Park, team.Endgame.ClimbTimer, team.Endgame.EndgameShoot,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("insert or replace into rebuilt2026_issues values(?, ?, ?, ?)",
+		id, team.Issues.Disconnect, team.Issues.LoseTrack, team.Issues.EverBeached,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("insert or replace into rebuilt2026_notes values(?, ?, ?, ?, ?, ?)",
+		id, team.Notes.Perf, team.Notes.Events, team.Notes.Comments, team.Notes.Teleop, team.Notes.Auto,
+	)
+	return err
+}
@@ -0,0 +1,179 @@
+package internal
+
+// Concurrent ingestion of submitted JSON through a bounded worker pool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The number of workers used if none are configured
+const kDefaultIngestWorkers = 4
+
+// How many file names can be waiting in the queue before new ones are left for the directory scan to pick up
+const kIngestQueueSize = 1024
+
+// How often the In directory is scanned for files that never made it into the queue (restarts, full queue, manual copies)
+const kIngestScanInterval = 5 * time.Second
+
+// The queue of files waiting in the In directory, along with the bookkeeping that
+// keeps entries for one match slot from being processed at the same time.
+type ingestQueue struct {
+	files chan string // The names of files in the In directory waiting to be processed
+
+	mutex     sync.Mutex
+	pending   map[string]bool      // Files that are either queued or currently being processed
+	slotLocks map[string]*slotLock // Locks held while a match slot is being processed
+}
+
+// A lock on one match slot, along with the number of workers waiting on it
+type slotLock struct {
+	lock    sync.Mutex
+	holders int
+}
+
+// The ingestion queue, held in memory
+var ingestion = &ingestQueue{
+	files:     make(chan string, kIngestQueueSize),
+	pending:   make(map[string]bool),
+	slotLocks: make(map[string]*slotLock),
+}
+
+// Queues a file in the In directory for processing. Returns false if it was already
+// queued or if the queue is full, in which case the next directory scan will pick it up.
+func EnqueueSubmission(fileName string) bool {
+	ingestion.mutex.Lock()
+	if ingestion.pending[fileName] {
+		ingestion.mutex.Unlock()
+		return false
+	}
+	ingestion.pending[fileName] = true
+	ingestion.mutex.Unlock()
+
+	select {
+	case ingestion.files <- fileName:
+		return true
+	default:
+		ingestion.mutex.Lock()
+		delete(ingestion.pending, fileName)
+		ingestion.mutex.Unlock()
+		return false
+	}
+}
+
+// Marks a file as no longer queued or processing
+func finishSubmission(fileName string) {
+	ingestion.mutex.Lock()
+	delete(ingestion.pending, fileName)
+	ingestion.mutex.Unlock()
+}
+
+// Returns the key identifying the match slot (event, match and driverstation) a file belongs to.
+// Pit scouting files are keyed by their event and team, as every visit to a team is merged onto one row.
+func slotKey(fileName string) string {
+	split := strings.Split(strings.TrimSuffix(fileName, ".json"), "_")
+	if isPitFile(fileName) {
+		return strings.Join(split[:min(len(split), 2)], "_")
+	}
+	return strings.Join(split[:3], "_")
+}
+
+// Blocks until the passed in match slot is free, then claims it
+func lockSlot(key string) {
+	ingestion.mutex.Lock()
+	slot, ok := ingestion.slotLocks[key]
+	if !ok {
+		slot = &slotLock{}
+		ingestion.slotLocks[key] = slot
+	}
+	slot.holders++
+	ingestion.mutex.Unlock()
+
+	slot.lock.Lock()
+}
+
+// Releases a match slot claimed with lockSlot(), forgetting it if nobody else is waiting
+func unlockSlot(key string) {
+	ingestion.mutex.Lock()
+	slot := ingestion.slotLocks[key]
+	slot.holders--
+	if slot.holders == 0 {
+		delete(ingestion.slotLocks, key)
+	}
+	ingestion.mutex.Unlock()
+
+	slot.lock.Unlock()
+}
+
+// Queues every json file in the In directory that isn't already queued
+func scanInDirectory() {
+	allJson, readErr := os.ReadDir(JsonInDirectory)
+	if readErr != nil {
+		LogErrorf(readErr, "Problem reading directory %v", JsonInDirectory)
+		return
+	}
+
+	for _, file := range allJson {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || isWaitingForRetry(file.Name()) {
+			continue
+		}
+		EnqueueSubmission(file.Name())
+	}
+}
+
+// Processes files from the queue until the queue is closed
+func ingestWorker() {
+	for fileName := range ingestion.files {
+		key := slotKey(fileName)
+
+		lockSlot(key)
+		// The file may have been handled by another worker while this one was waiting on the slot
+		if _, statErr := os.Stat(filepath.Join(JsonInDirectory, fileName)); statErr == nil {
+			processSubmission(fileName)
+		}
+		unlockSlot(key)
+
+		finishSubmission(fileName)
+	}
+}
+
+// Starts the configured number of ingestion workers. TotalSetup fills in the default if none are configured.
+func startIngestWorkers() {
+	workers := CachedConfigs.IngestWorkers
+
+	for i := 0; i < workers; i++ {
+		go ingestWorker()
+	}
+
+	LogMessagef("Started %v ingestion workers", workers)
+}
+
+// Writes submitted data to the In directory and queues it for processing.
+// The data is written to the passed in temporary file first so the directory scan never sees a half-written entry.
+func writeSubmission(fileName string, tempFile *os.File, data []byte) bool {
+	finalPath := filepath.Join(JsonInDirectory, fileName)
+	tempPath := tempFile.Name()
+
+	_, writeErr := tempFile.Write(data)
+	if closeErr := tempFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		LogErrorf(writeErr, "Problem writing %v", tempPath)
+		os.Remove(tempPath)
+		return false
+	}
+	os.Chmod(tempPath, 0777)
+
+	if renameErr := os.Rename(tempPath, finalPath); renameErr != nil {
+		LogErrorf(renameErr, "Problem moving %v to %v", tempPath, finalPath)
+		os.Remove(tempPath)
+		return false
+	}
+
+	EnqueueSubmission(fileName)
+	return true
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlotKey(t *testing.T) {
+	cases := map[string]string{
+		"2024cabl_12_red1_1700000000000.json":  "2024cabl_12_red1",
+		"2024cabl_12_blue3_1700000000001.json": "2024cabl_12_blue3",
+		"2024cabl_1816_1700000000000.json":     "2024cabl_1816",
+		"2024cabl_1816.json":                   "2024cabl_1816",
+	}
+
+	for fileName, want := range cases {
+		if got := slotKey(fileName); got != want {
+			t.Errorf("slotKey(%q) = %q, want %q", fileName, got, want)
+		}
+	}
+
+	if slotKey("2024cabl_12_red1_1.json") == slotKey("2024cabl_12_red2_1.json") {
+		t.Error("different driverstations share a slot")
+	}
+}
+
+func TestSlotLockSerializesOneSlot(t *testing.T) {
+	const workers = 8
+	key := "2024cabl_12_red1"
+
+	var wg sync.WaitGroup
+	var active, maxActive int
+	var counter sync.Mutex
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lockSlot(key)
+			defer unlockSlot(key)
+
+			counter.Lock()
+			active++
+			maxActive = max(maxActive, active)
+			counter.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			counter.Lock()
+			active--
+			counter.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("%v workers held the same slot at once", maxActive)
+	}
+
+	ingestion.mutex.Lock()
+	defer ingestion.mutex.Unlock()
+	if _, ok := ingestion.slotLocks[key]; ok {
+		t.Error("slot lock was kept after every worker released it")
+	}
+}
+
+func TestSlotLockAllowsOtherSlots(t *testing.T) {
+	lockSlot("2024cabl_12_red1")
+	defer unlockSlot("2024cabl_12_red1")
+
+	done := make(chan struct{})
+	go func() {
+		lockSlot("2024cabl_12_red2")
+		unlockSlot("2024cabl_12_red2")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking one slot blocked another")
+	}
+}
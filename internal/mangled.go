@@ -0,0 +1,167 @@
+package internal
+
+// Keeping submissions that couldn't be parsed, so an admin can fix them up and send them through again
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of submissions that can be mangled
+const (
+	MangledMatch = "match" // Sent to /dataEntry
+	MangledPit   = "pit"   // Sent to /pitScout
+)
+
+// A submission that couldn't be parsed, stored in full in the Mangled directory
+type MangledRecord struct {
+	ID       string    `json:"ID"`       // The name of the record in Mangled, without .json
+	Kind     string    `json:"Kind"`     // MangledMatch or MangledPit
+	Scouter  string    `json:"Scouter"`  // The authenticated user who submitted it
+	Event    string    `json:"Event"`    // The event key it was submitted under
+	Received time.Time `json:"Received"` // When it was submitted
+	Error    string    `json:"Error"`    // Why it couldn't be parsed
+	Payload  string    `json:"Payload"`  // Exactly what was submitted
+
+	RepairedAs string    `json:"RepairedAs,omitempty"` // The name of the file the repaired version was queued as
+	RepairedBy string    `json:"RepairedBy,omitempty"` // The admin who repaired it
+	Repaired   time.Time `json:"Repaired,omitempty"`   // When it was repaired
+}
+
+// Stores a submission that couldn't be parsed, returning the ID of its record
+func StoreMangled(kind string, scouter string, payload []byte, parseErr error) string {
+	id, tempFile, reserveErr := reserveFileName(JsonMangledDirectory, kind+"_%v")
+	if reserveErr != nil {
+		LogErrorf(reserveErr, "Problem naming a mangled %v submission from %v", kind, scouter)
+		return ""
+	}
+	defer releaseFileName(tempFile)
+
+	record := MangledRecord{
+		ID:       id,
+		Kind:     kind,
+		Scouter:  scouter,
+		Event:    GetCurrentEvent(),
+		Received: time.Now(),
+		Error:    parseErr.Error(),
+		Payload:  string(payload),
+	}
+
+	writeMangledRecord(record)
+	LogMessagef("Stored mangled %v submission from %v as %v", kind, scouter, record.ID)
+
+	return record.ID
+}
+
+// Writes a MangledRecord to the Mangled directory, overwriting any older version of it
+func writeMangledRecord(record MangledRecord) {
+	recordBytes, marshalErr := json.MarshalIndent(record, "", "  ")
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem marshalling mangled record %v", record.ID)
+		return
+	}
+
+	recordPath := filepath.Join(JsonMangledDirectory, record.ID+".json")
+	if writeErr := WriteFileWithPermissions(recordPath, recordBytes); writeErr != nil {
+		LogErrorf(writeErr, "Problem writing %v", recordPath)
+	}
+}
+
+// Reads one MangledRecord, returning false if there isn't one by that ID
+func GetMangledRecord(id string) (MangledRecord, bool) {
+	var record MangledRecord
+
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return record, false
+	}
+
+	recordBytes, readErr := readFileIfExists(filepath.Join(JsonMangledDirectory, id+".json"))
+	if readErr != nil || recordBytes == nil {
+		return record, false
+	}
+
+	if unmarshalErr := json.Unmarshal(recordBytes, &record); unmarshalErr != nil || record.ID == "" {
+		return record, false
+	}
+
+	return record, true
+}
+
+// Reads every MangledRecord, newest first. Files in Mangled from before records were kept are skipped.
+func GetMangledRecords() []MangledRecord {
+	records := []MangledRecord{}
+
+	mangledJson, readErr := os.ReadDir(JsonMangledDirectory)
+	if readErr != nil {
+		LogErrorf(readErr, "Problem reading %v", JsonMangledDirectory)
+		return records
+	}
+
+	for _, file := range mangledJson {
+		if record, ok := GetMangledRecord(strings.TrimSuffix(file.Name(), ".json")); ok {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Received.After(records[j].Received)
+	})
+
+	return records
+}
+
+// Sends a mangled submission back through the normal pipeline, as if its original scouter had submitted it.
+// The corrected payload is used if one is passed in, otherwise the original is tried again.
+// Returns the result of the submission, or an error if the payload still doesn't parse.
+func RepairMangled(id string, corrected []byte, repairedBy string) (SubmissionResult, error) {
+	record, ok := GetMangledRecord(id)
+	if !ok {
+		return SubmissionResult{}, errors.New("no mangled submission " + id)
+	}
+
+	if record.Event != GetCurrentEvent() {
+		return SubmissionResult{}, errors.New(id + " was submitted during " + record.Event + ", not the current event")
+	}
+
+	payload := corrected
+	if len(payload) == 0 {
+		payload = []byte(record.Payload)
+	}
+
+	season := CurrentSeason()
+
+	var result SubmissionResult
+	switch record.Kind {
+	case MangledMatch:
+		team, decodeErr := season.DecodeMatch(payload)
+		if decodeErr != nil {
+			return result, decodeErr
+		}
+		team.SetScouter(record.Scouter)
+		result = SubmitMatchEntry(team)
+	case MangledPit:
+		pit, decodeErr := season.DecodePit(payload)
+		if decodeErr != nil {
+			return result, decodeErr
+		}
+		result = SubmitPitEntry(pit)
+	default:
+		return result, errors.New("unknown kind of submission " + record.Kind)
+	}
+
+	if result.Status != SubmissionRejected {
+		record.RepairedAs = result.File
+		record.RepairedBy = repairedBy
+		record.Repaired = time.Now()
+		writeMangledRecord(record)
+
+		LogMessagef("%v repaired mangled submission %v as %v", repairedBy, id, result.File)
+	}
+
+	return result, nil
+}
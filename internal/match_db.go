@@ -0,0 +1,742 @@
+package internal
+
+// Utilities for interacting with matches.db, the source of truth for all parsed scouting data.
+// The spreadsheet is only a projection of this, and can be rebuilt from it with RebuildSheetFromDB().
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The reference to matches.db
+var matchDB *sql.DB
+
+// Where a submission currently is in the ingestion pipeline
+type EntryState string
+
+// Entry state enum
+const (
+	EntryQueued    EntryState = "queued"    // Waiting in the In directory
+	EntryWritten   EntryState = "written"   // Written to the sheet, in Written, PitWritten or Prescouted
+	EntryErrored   EntryState = "errored"   // Given up on, in Errored
+	EntryDiscarded EntryState = "discarded" // Superseded or thrown out, in Discarded
+)
+
+// The tables of matches.db shared by every season. Seasons add their own tables for the sections of their entries.
+var matchDBSchema = []string{
+	`create table if not exists entries(
+		id integer primary key autoincrement,
+		file text not null unique,
+		event text not null,
+		matchnum int not null,
+		replay int not null,
+		team int not null,
+		scouter text,
+		isblue int not null,
+		station int not null,
+		rescouting int not null,
+		prescouting int not null,
+		state text not null,
+		received int not null,
+		raw text not null
+	)`,
+	`create index if not exists idx_entries_replay_slot on entries(event, matchnum, replay, isblue, station, state)`,
+	`create table if not exists pit(
+		id integer primary key autoincrement,
+		file text not null unique,
+		event text not null,
+		team int not null,
+		scouter text,
+		state text not null,
+		received int not null,
+		raw text not null
+	)`,
+	`create index if not exists idx_pit_team on pit(event, team)`,
+	`create table if not exists prescouting(
+		id integer primary key autoincrement,
+		file text not null unique,
+		event text not null,
+		team int not null,
+		scouter text,
+		state text not null,
+		received int not null,
+		raw text not null
+	)`,
+	`create index if not exists idx_prescouting_team on prescouting(event, team)`,
+	`create table if not exists team_rows(
+		event text not null,
+		tab text not null,
+		team int not null,
+		row int not null,
+		primary key(event, tab, team)
+	)`,
+	`create table if not exists entry_rows(
+		event text not null,
+		file text not null,
+		row int not null,
+		primary key(event, file)
+	)`,
+	`create table if not exists submissions(
+		id text primary key,
+		file text not null,
+		scouter text,
+		received int not null
+	)`,
+	`create table if not exists pipeline_audit(
+		id integer primary key,
+		time int not null,
+		admin text not null,
+		action text not null,
+		file text not null,
+		fromdir text not null,
+		todir text not null,
+		note text not null
+	)`,
+	`create index if not exists idx_pipeline_audit_file on pipeline_audit(file)`,
+	`create table if not exists entry_warnings(
+		id integer primary key,
+		file text not null,
+		field text not null,
+		message text not null
+	)`,
+	`create index if not exists idx_entry_warnings_file on entry_warnings(file)`,
+	`create table if not exists merges(
+		event text not null,
+		matchnum int not null,
+		replay int not null,
+		station text not null,
+		team int,
+		merged text not null,
+		report text not null,
+		disagreements int not null,
+		updated int not null,
+		primary key(event, matchnum, replay, station)
+	)`,
+	`create table if not exists entry_versions(
+		id integer primary key,
+		event text not null,
+		matchnum int not null,
+		replay int not null,
+		station text not null,
+		version int not null,
+		files text not null,
+		supersedes int not null,
+		action text not null,
+		author text not null,
+		created int not null
+	)`,
+	`create index if not exists idx_entry_versions_slot on entry_versions(event, matchnum, replay, station)`,
+	`create table if not exists sink_status(
+		name text primary key,
+		type text not null,
+		writes int not null,
+		failures int not null,
+		lastsuccess int not null,
+		lastfailure int not null,
+		lasterror text not null,
+		stale int not null
+	)`,
+	`create table if not exists sheet_provisioning(
+		event text not null,
+		spreadsheet text not null,
+		created int not null,
+		provisioned int not null,
+		primary key(event, spreadsheet)
+	)`,
+}
+
+// Opens matches.db, creating any missing tables, and imports anything in Written, PitWritten and Prescouted it doesn't know about yet.
+func InitMatchDB() {
+	dbPath := filepath.Join(CachedConfigs.PathToDatabases, "matches.db")
+	dbRef, err := sql.Open(CachedConfigs.SqliteDriver, dbPath+"?_busy_timeout=5000")
+	if err != nil {
+		FatalError(err, "Problem opening database "+dbPath)
+	}
+	matchDB = dbRef
+
+	schema := matchDBSchema
+	for _, season := range seasons {
+		schema = append(schema, season.Tables...)
+	}
+
+	for _, statement := range schema {
+		if _, execErr := matchDB.Exec(statement); execErr != nil {
+			FatalError(execErr, "Problem creating table in "+dbPath)
+		}
+	}
+
+	importProcessedJson()
+}
+
+// A match entry as it is stored in matches.db
+type StoredEntry struct {
+	File     string     // The name of the submitted file
+	Event    string     // The event key it was submitted under
+	State    EntryState // Where it currently is in the pipeline
+	Received time.Time  // When it was submitted
+	Data     MatchEntry // The parsed data, in the format of the event's season
+}
+
+// A pit scouting entry as it is stored in matches.db
+type StoredPitEntry struct {
+	File     string     // The name of the submitted file
+	Event    string     // The event key it was submitted under
+	State    EntryState // Where it currently is in the pipeline
+	Received time.Time  // When it was submitted
+	Data     PitEntry   // The parsed data, in the format of the event's season
+}
+
+// Gets the event key out of a submitted file name (EVENT_...)
+func eventFromFileName(fileName string) string {
+	return strings.Split(fileName, "_")[0]
+}
+
+// Returns if a submitted file is pit scouting (EVENT_TEAM_SystemTimeMS.json, or EVENT_TEAM.json from before every visit was kept)
+func isPitFile(fileName string) bool {
+	return len(strings.Split(strings.TrimSuffix(fileName, ".json"), "_")) <= 3
+}
+
+// Gets the submission time out of a submitted file name (..._SystemTimeMS.json), falling back to now.
+func receivedFromFileName(fileName string) time.Time {
+	split := strings.Split(strings.TrimSuffix(fileName, ".json"), "_")
+	if millis, err := strconv.ParseInt(split[len(split)-1], 10, 64); err == nil && len(split) > 2 {
+		return time.UnixMilli(millis)
+	}
+	return time.Now()
+}
+
+// Stores a parsed match entry in matches.db, replacing anything already stored for that file.
+// The shared parts go in entries, and the season of the event breaks out the rest into its own tables.
+func StoreMatchEntry(fileName string, entry MatchEntry, state EntryState) error {
+	raw, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	event := eventFromFileName(fileName)
+	info := entry.Info()
+
+	tx, err := matchDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var id int64
+	err = tx.QueryRow(
+		`insert into entries(file, event, matchnum, replay, team, scouter, isblue, station, rescouting, prescouting, state, received, raw)
+		values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		on conflict(file) do update set
+			matchnum = excluded.matchnum, replay = excluded.replay, team = excluded.team, scouter = excluded.scouter,
+			isblue = excluded.isblue, station = excluded.station, rescouting = excluded.rescouting,
+			prescouting = excluded.prescouting, state = excluded.state, raw = excluded.raw
+		returning id`,
+		fileName, event, info.Match.Number, info.Match.IsReplay, info.TeamNumber, info.Scouter,
+		info.DriverStation.IsBlue, info.DriverStation.Number, info.Rescouting, info.Prescouting,
+		state, receivedFromFileName(fileName).UnixMilli(), string(raw),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if submissionID, ok := canonicalSubmissionID(info.SubmissionID); ok && submissionID != "" {
+		if _, err = tx.Exec("insert or ignore into submissions values(?, ?, ?, ?)",
+			submissionID, fileName, info.Scouter, receivedFromFileName(fileName).UnixMilli(),
+		); err != nil {
+			return err
+		}
+	}
+
+	if season := SeasonForEvent(event); season.StoreMatch != nil {
+		if err = season.StoreMatch(tx, id, entry); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+// Stores a parsed prescouting entry in matches.db, replacing anything already stored for that file.
+// Prescouting isn't from the event itself, so it is kept apart from match entries and left out of everything worked out from matches.
+func StorePrescoutEntry(fileName string, entry MatchEntry, state EntryState) error {
+	raw, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	info := entry.Info()
+	_, err := matchDB.Exec(
+		`insert into prescouting(file, event, team, scouter, state, received, raw)
+		values(?, ?, ?, ?, ?, ?, ?)
+		on conflict(file) do update set
+			team = excluded.team, scouter = excluded.scouter, state = excluded.state, raw = excluded.raw`,
+		fileName, eventFromFileName(fileName), info.TeamNumber, info.Scouter, state, receivedFromFileName(fileName).UnixMilli(), string(raw),
+	)
+	return err
+}
+
+// Stores a parsed pit scouting entry in matches.db, replacing anything already stored for that file.
+func StorePitEntry(fileName string, pit PitEntry, state EntryState) error {
+	raw, marshalErr := json.Marshal(pit)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	event := eventFromFileName(fileName)
+
+	tx, err := matchDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var id int64
+	err = tx.QueryRow(
+		`insert into pit(file, event, team, scouter, state, received, raw)
+		values(?, ?, ?, ?, ?, ?, ?)
+		on conflict(file) do update set
+			team = excluded.team, scouter = excluded.scouter, state = excluded.state, raw = excluded.raw
+		returning id`,
+		fileName, event, pit.Team(), pit.ScoutedBy(), state, receivedFromFileName(fileName).UnixMilli(), string(raw),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if season := SeasonForEvent(event); season.StorePitEntry != nil {
+		if err = season.StorePitEntry(tx, id, pit); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+// Updates the pipeline state of a stored match, pit or prescouting entry, which can change the Coverage tab
+func SetEntryState(fileName string, state EntryState) {
+	defer MarkCoverageStale()
+
+	if _, err := matchDB.Exec("update entries set state = ? where file = ?", state, fileName); err != nil {
+		LogErrorf(err, "Problem executing sql query UPDATE entries SET state = ? WHERE file = ? with args: %v, %v", state, fileName)
+	}
+	if _, err := matchDB.Exec("update pit set state = ? where file = ?", state, fileName); err != nil {
+		LogErrorf(err, "Problem executing sql query UPDATE pit SET state = ? WHERE file = ? with args: %v, %v", state, fileName)
+	}
+	if _, err := matchDB.Exec("update prescouting set state = ? where file = ?", state, fileName); err != nil {
+		LogErrorf(err, "Problem executing sql query UPDATE prescouting SET state = ? WHERE file = ? with args: %v, %v", state, fileName)
+	}
+}
+
+// The columns selected by every query that loads entries back out of matches.db
+const storedEntryColumns = "file, event, state, received, raw"
+
+// Scans one row selected with storedEntryColumns into a StoredEntry, decoding it in the format of its event's season
+func scanStoredEntry(rows *sql.Rows) (StoredEntry, error) {
+	var entry StoredEntry
+	var received int64
+	var raw string
+
+	if err := rows.Scan(&entry.File, &entry.Event, &entry.State, &received, &raw); err != nil {
+		return entry, err
+	}
+	entry.Received = time.UnixMilli(received)
+
+	data, err := SeasonForEvent(entry.Event).DecodeMatch([]byte(raw))
+	entry.Data = data
+
+	return entry, err
+}
+
+// Runs a query selecting storedEntryColumns and collects every row into StoredEntries.
+// Rows that can't be read are skipped, but still make it return an error.
+func queryStoredEntries(query string, args ...any) ([]StoredEntry, error) {
+	var entries []StoredEntry
+
+	rows, err := matchDB.Query(query, args...)
+	if err != nil {
+		LogErrorf(err, "Problem executing sql query %v with args %v", query, args)
+		return entries, err
+	}
+	defer rows.Close()
+
+	var errs []error
+	for rows.Next() {
+		entry, scanErr := scanStoredEntry(rows)
+		if scanErr != nil {
+			LogErrorf(scanErr, "Problem scanning response to sql query %v with args %v", query, args)
+			errs = append(errs, scanErr)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, errors.Join(append(errs, rows.Err())...)
+}
+
+// Gets every stored match entry of an event in the passed in state, ordered by match and driverstation.
+// Prescouted entries are left out, see GetStoredPrescoutEntries().
+func GetStoredEntries(event string, state EntryState) ([]StoredEntry, error) {
+	return queryStoredEntries(
+		"select "+storedEntryColumns+" from entries where event = ? and state = ? and prescouting = 0 order by matchnum, isblue, station, received",
+		event, state,
+	)
+}
+
+// Gets every stored prescouting entry of an event in the passed in state, oldest first
+func GetStoredPrescoutEntries(event string, state EntryState) ([]StoredEntry, error) {
+	return queryStoredEntries("select "+storedEntryColumns+" from prescouting where event = ? and state = ? order by received", event, state)
+}
+
+// Gets the row of a team on a tab with one row per team (PitScouting and Prescouting).
+// Teams get the next free row the first time they are written to the tab, and keep it after that.
+func GetTeamRow(event string, tab string, team int) (int, error) {
+	_, err := matchDB.Exec(
+		`insert into team_rows(event, tab, team, row)
+		select ?, ?, ?, coalesce(max(row), 1) + 1 from team_rows where event = ? and tab = ?
+		on conflict(event, tab, team) do nothing`,
+		event, tab, team, event, tab,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var row int
+	err = matchDB.QueryRow("select row from team_rows where event = ? and tab = ? and team = ?", event, tab, team).Scan(&row)
+	return row, err
+}
+
+// Gets the RawData row of an entry when not multi-scouting, where every entry has its own row.
+// Entries get the next free row the first time they are written, and keep it after that.
+func GetEntryRow(event string, fileName string) (int, error) {
+	_, err := matchDB.Exec(
+		`insert into entry_rows(event, file, row)
+		select ?, ?, coalesce(max(row), 1) + 1 from entry_rows where event = ?
+		on conflict(event, file) do nothing`,
+		event, fileName, event,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var row int
+	err = matchDB.QueryRow("select row from entry_rows where event = ? and file = ?", event, fileName).Scan(&row)
+	return row, err
+}
+
+// Gets the stored match entry of a single file, returning false if there isn't one
+func GetStoredEntry(fileName string) (StoredEntry, bool) {
+	entries, _ := queryStoredEntries("select "+storedEntryColumns+" from entries where file = ?", fileName)
+	if len(entries) == 0 {
+		return StoredEntry{}, false
+	}
+	return entries[0], true
+}
+
+// Runs a query selecting storedEntryColumns from pit and collects every row into StoredPitEntries.
+// Rows that can't be read are skipped, but still make it return an error.
+func queryStoredPitEntries(query string, args ...any) ([]StoredPitEntry, error) {
+	var entries []StoredPitEntry
+
+	rows, err := matchDB.Query(query, args...)
+	if err != nil {
+		LogErrorf(err, "Problem executing sql query %v with args %v", query, args)
+		return entries, err
+	}
+	defer rows.Close()
+
+	var errs []error
+	for rows.Next() {
+		var entry StoredPitEntry
+		var received int64
+		var raw string
+
+		if scanErr := rows.Scan(&entry.File, &entry.Event, &entry.State, &received, &raw); scanErr != nil {
+			LogErrorf(scanErr, "Problem scanning response to sql query %v with args %v", query, args)
+			errs = append(errs, scanErr)
+			continue
+		}
+
+		data, decodeErr := SeasonForEvent(entry.Event).DecodePit([]byte(raw))
+		if decodeErr != nil {
+			LogErrorf(decodeErr, "Problem unmarshalling stored pit entry %v", entry.File)
+			errs = append(errs, decodeErr)
+			continue
+		}
+		entry.Data = data
+
+		entry.Received = time.UnixMilli(received)
+		entries = append(entries, entry)
+	}
+
+	return entries, errors.Join(append(errs, rows.Err())...)
+}
+
+// Gets every stored pit scouting entry of an event in the passed in state, oldest first
+func GetStoredPitEntries(event string, state EntryState) ([]StoredPitEntry, error) {
+	return queryStoredPitEntries("select "+storedEntryColumns+" from pit where event = ? and state = ? order by received", event, state)
+}
+
+// Gets every written visit to a team's pit at an event, oldest first
+func GetPitVisits(event string, team int) ([]StoredPitEntry, error) {
+	return queryStoredPitEntries("select "+storedEntryColumns+" from pit where event = ? and team = ? and state = ? order by received", event, team, EntryWritten)
+}
+
+// Gets the names of everyone whose entry for a match and driverstation has been written
+func GetWrittenScouters(event string, match int, isBlue bool, station int) []string {
+	var names []string
+
+	rows, err := matchDB.Query(
+		"select scouter from entries where event = ? and matchnum = ? and isblue = ? and station = ? and state = ? and prescouting = 0 order by received",
+		event, match, isBlue, station, EntryWritten,
+	)
+	if err != nil {
+		LogErrorf(err, "Problem looking up scouters of match %v", match)
+		return names
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if scanErr := rows.Scan(&name); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to scouter lookup")
+			continue
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Gets the files of every stored entry in the passed in state for one match and driverstation, oldest first.
+// Replays are kept apart from the original match.
+func GetSlotFiles(event string, match MatchInfo, isBlue bool, station int, state EntryState) []string {
+	var files []string
+
+	rows, err := matchDB.Query(
+		"select file from entries where event = ? and matchnum = ? and replay = ? and isblue = ? and station = ? and state = ? and prescouting = 0 order by received",
+		event, match.Number, match.IsReplay, isBlue, station, state,
+	)
+	if err != nil {
+		LogErrorf(err, "Problem looking up entries of match %v", match.Number)
+		return files
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file string
+		if scanErr := rows.Scan(&file); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to entry lookup")
+			continue
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// Claims a client submission ID for the passed in file. If the ID was already claimed,
+// the file it was claimed by is returned instead, along with false.
+func ClaimSubmissionID(id string, fileName string, scouter string) (string, bool, error) {
+	result, err := matchDB.Exec("insert or ignore into submissions values(?, ?, ?, ?)", id, fileName, scouter, time.Now().UnixMilli())
+	if err != nil {
+		return "", false, err
+	}
+
+	if claimed, _ := result.RowsAffected(); claimed > 0 {
+		return fileName, true, nil
+	}
+
+	var existing string
+	err = matchDB.QueryRow("select file from submissions where id = ?", id).Scan(&existing)
+	return existing, false, err
+}
+
+// Releases a submission ID claimed with ClaimSubmissionID(), used when the entry couldn't be stored after all
+func ReleaseSubmissionID(id string) {
+	if _, err := matchDB.Exec("delete from submissions where id = ?", id); err != nil {
+		LogErrorf(err, "Problem releasing submission id %v", id)
+	}
+}
+
+// Stores any entries in Written, PitWritten and Prescouted that matches.db doesn't know about yet.
+// This lets servers that processed entries before matches.db existed pick them back up.
+func importProcessedJson() {
+	known := make(map[string]bool)
+
+	for _, table := range []string{"entries", "pit", "prescouting"} {
+		rows, err := matchDB.Query("select file from " + table)
+		if err != nil {
+			LogErrorf(err, "Problem reading stored files from %v", table)
+			return
+		}
+		for rows.Next() {
+			var file string
+			if rows.Scan(&file) == nil {
+				known[file] = true
+			}
+		}
+		rows.Close()
+	}
+
+	imported := 0
+
+	writtenJson, readErr := os.ReadDir(JsonWrittenDirectory)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		LogErrorf(readErr, "Problem reading %v", JsonWrittenDirectory)
+	}
+	for _, file := range writtenJson {
+		if known[file.Name()] || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		entry, parseErr := readMatchEntry(filepath.Join(JsonWrittenDirectory, file.Name()))
+		if parseErr != nil {
+			continue
+		}
+
+		if storeErr := StoreMatchEntry(file.Name(), entry, EntryWritten); storeErr != nil {
+			LogErrorf(storeErr, "Problem importing %v into matches.db", file.Name())
+			continue
+		}
+		imported++
+	}
+
+	prescoutJson, readErr := os.ReadDir(JsonPrescoutedDirectory)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		LogErrorf(readErr, "Problem reading %v", JsonPrescoutedDirectory)
+	}
+	for _, file := range prescoutJson {
+		if known[file.Name()] || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		entry, parseErr := readMatchEntry(filepath.Join(JsonPrescoutedDirectory, file.Name()))
+		if parseErr != nil {
+			continue
+		}
+
+		if storeErr := StorePrescoutEntry(file.Name(), entry, EntryWritten); storeErr != nil {
+			LogErrorf(storeErr, "Problem importing %v into matches.db", file.Name())
+			continue
+		}
+		imported++
+	}
+
+	pitJson, readErr := os.ReadDir(JsonPitWrittenDirectory)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		LogErrorf(readErr, "Problem reading %v", JsonPitWrittenDirectory)
+	}
+	for _, file := range pitJson {
+		if known[file.Name()] || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		pit, parseErr := readPitEntry(filepath.Join(JsonPitWrittenDirectory, file.Name()))
+		if parseErr != nil {
+			continue
+		}
+
+		if storeErr := StorePitEntry(file.Name(), pit, EntryWritten); storeErr != nil {
+			LogErrorf(storeErr, "Problem importing %v into matches.db", file.Name())
+			continue
+		}
+		imported++
+	}
+
+	if imported > 0 {
+		LogMessagef("Imported %v previously processed entries into matches.db", imported)
+	}
+}
+
+// Stores the merged row of a multi-scouted match and the report of what its scouters disagreed on, replacing any older merge of it
+func StoreMergeResult(merged []interface{}, report DisagreementReport) {
+	mergedBytes, mergedErr := json.Marshal(merged)
+	if mergedErr != nil {
+		LogErrorf(mergedErr, "Problem encoding %v", merged)
+		return
+	}
+
+	reportBytes, reportErr := json.Marshal(report)
+	if reportErr != nil {
+		LogErrorf(reportErr, "Problem encoding %v", report)
+		return
+	}
+
+	_, err := matchDB.Exec(
+		`insert into merges(event, matchnum, replay, station, team, merged, report, disagreements, updated) values(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		on conflict(event, matchnum, replay, station) do update set
+			team = excluded.team, merged = excluded.merged, report = excluded.report,
+			disagreements = excluded.disagreements, updated = excluded.updated`,
+		report.Event, report.Match, report.Replay, report.DriverStation, report.Team, string(mergedBytes), string(reportBytes),
+		len(report.Disagreements), report.Updated.UnixMilli(),
+	)
+	if err != nil {
+		LogErrorf(err, "Problem storing merge of %v match %v %v", report.Event, report.Match, report.DriverStation)
+		return
+	}
+	MarkReliabilityStale()
+}
+
+// Forgets the stored merge of a match (or its replay) and driverstation, for when only one entry is left on its row
+func ClearMergeResult(event string, match MatchInfo, ds string) {
+	if _, err := matchDB.Exec("delete from merges where event = ? and matchnum = ? and replay = ? and station = ?", event, match.Number, match.IsReplay, ds); err != nil {
+		LogErrorf(err, "Problem clearing merge of %v match %v %v", event, match, ds)
+		return
+	}
+	MarkReliabilityStale()
+}
+
+// Gets the disagreement reports of an event, ordered by match, replay and driverstation.
+// Passing in a match gets every report of that match, otherwise only reports with disagreements are returned.
+func GetDisagreementReports(event string, match uint) []DisagreementReport {
+	reports := []DisagreementReport{}
+
+	query := "select report from merges where event = ? and disagreements > 0 order by matchnum, replay, station"
+	args := []any{event}
+	if match != 0 {
+		query = "select report from merges where event = ? and matchnum = ? order by replay, station"
+		args = append(args, match)
+	}
+
+	rows, err := matchDB.Query(query, args...)
+	if err != nil {
+		LogErrorf(err, "Problem executing sql query %v with args %v", query, args)
+		return reports
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw string
+		if scanErr := rows.Scan(&raw); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to disagreement query")
+			continue
+		}
+
+		var report DisagreementReport
+		if unmarshalErr := json.Unmarshal([]byte(raw), &report); unmarshalErr != nil {
+			LogErrorf(unmarshalErr, "Problem decoding disagreement report %v", raw)
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	return reports
+}
@@ -0,0 +1,217 @@
+package internal
+
+// Configurable rules for combining each field when multi-scouting
+
+import (
+	"slices"
+
+	"github.com/montanaflynn/stats"
+)
+
+// How the answers of several scouters to one field are combined
+type MergeStrategy string
+
+// Merge strategy enum
+const (
+	MergeMean        MergeStrategy = "mean"        // The average. Numbers only.
+	MergeNonzeroMean MergeStrategy = "nonzeroMean" // The average of every answer that isn't 0, for timers scouters leave at 0 when nothing happened. Numbers only.
+	MergeMedian      MergeStrategy = "median"      // The middle answer. Numbers only.
+	MergeMin         MergeStrategy = "min"         // The lowest answer. Numbers only.
+	MergeMax         MergeStrategy = "max"         // The highest answer. Numbers only.
+	MergeMode        MergeStrategy = "mode"        // The answer given most, with ties going to whoever answered first
+	MergeAny         MergeStrategy = "any"         // True if any scouter said so. Booleans only.
+	MergeAll         MergeStrategy = "all"         // True if every scouter said so. Booleans only.
+	MergeReliable    MergeStrategy = "reliable"    // The answer of the most reliable scouter
+)
+
+// The strategies that can be used on each kind of field
+var (
+	numberStrategies = []MergeStrategy{MergeMean, MergeNonzeroMean, MergeMedian, MergeMin, MergeMax, MergeMode, MergeReliable}
+	boolStrategies   = []MergeStrategy{MergeAny, MergeAll, MergeMode, MergeReliable}
+	stringStrategies = []MergeStrategy{MergeMode, MergeReliable}
+)
+
+// Logs any configured merge strategy that doesn't exist or is for a field the season's entries don't have,
+// so typos in the config don't go unnoticed
+func CheckMergeStrategies(configured map[string]string, season *GameSeason) {
+	fields := flattenEntry(season.NewMatchEntry())
+
+	for field, strategy := range configured {
+		if !slices.Contains(numberStrategies, MergeStrategy(strategy)) && !slices.Contains(boolStrategies, MergeStrategy(strategy)) {
+			LogMessagef("Unknown merge strategy %v for %v, its default will be used instead", strategy, field)
+		}
+		if _, known := fields[field]; !known {
+			LogMessagef("Merge strategy %v is for %v, which %v entries don't have, so it won't be used", strategy, field, season.Name)
+		}
+	}
+}
+
+// Combines the answers of the scouters of one match, field by field, following the configured merge strategies
+type fieldMerger struct {
+	scouters   []string          // The scouters, in the same order as the answers passed in
+	weights    []float64         // How reliable each scouter is
+	weighted   bool              // If means and modes are weighted by how reliable each scouter is
+	configured map[string]string // The configured strategies, by the json path of the field
+}
+
+// Creates a fieldMerger for the passed in scouters using the strategies from the config
+func newFieldMerger(scouters []string) *fieldMerger {
+	merger := &fieldMerger{
+		scouters:   scouters,
+		weighted:   CachedConfigs.WeighByReliability,
+		configured: CachedConfigs.MergeStrategies,
+	}
+
+	for _, scouter := range scouters {
+		merger.weights = append(merger.weights, ScouterReliability(scouter))
+	}
+
+	return merger
+}
+
+// Gets the strategy configured for a field, or the fallback if none is configured or it can't be used on that kind of field
+func (merger *fieldMerger) strategy(field string, allowed []MergeStrategy, fallback MergeStrategy) MergeStrategy {
+	if configured, ok := merger.configured[field]; ok && slices.Contains(allowed, MergeStrategy(configured)) {
+		return MergeStrategy(configured)
+	}
+	return fallback
+}
+
+// Gets how much a scouter's answer counts towards a mean or mode
+func (merger *fieldMerger) weight(i int) float64 {
+	if merger.weighted {
+		return merger.weights[i]
+	}
+	return 1
+}
+
+// Gets the index of the most reliable scouter whose answer counts. Ties go to whoever answered first.
+func (merger *fieldMerger) mostReliable(counts func(i int) bool) int {
+	best := -1
+	for i := range merger.scouters {
+		if !counts(i) {
+			continue
+		}
+		if best == -1 || merger.weights[i] > merger.weights[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Averages the answers that count, weighted if configured to be
+func (merger *fieldMerger) mean(values []float64, counts func(i int) bool) float64 {
+	var sum, totalWeight float64
+	for i, value := range values {
+		if counts(i) {
+			sum += value * merger.weight(i)
+			totalWeight += merger.weight(i)
+		}
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+	return sum / totalWeight
+}
+
+// Gets the index of the answer given the most out of the ones that count, weighted if configured to be. Ties go to whoever answered first.
+func (merger *fieldMerger) mode(count int, equal func(i, j int) bool, counts func(i int) bool) int {
+	best, bestVotes := -1, 0.0
+	for i := 0; i < count; i++ {
+		if !counts(i) {
+			continue
+		}
+
+		votes := 0.0
+		for j := 0; j < count; j++ {
+			if counts(j) && equal(i, j) {
+				votes += merger.weight(j)
+			}
+		}
+
+		if best == -1 || votes > bestVotes {
+			best, bestVotes = i, votes
+		}
+	}
+	return best
+}
+
+// Every answer counts
+func allAnswers(int) bool { return true }
+
+// Combines numeric answers to a field
+func (merger *fieldMerger) Number(field string, values []float64, fallback MergeStrategy) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var result float64
+	var err error
+
+	switch merger.strategy(field, numberStrategies, fallback) {
+	case MergeMean:
+		result = merger.mean(values, allAnswers)
+	case MergeNonzeroMean:
+		result = merger.mean(values, func(i int) bool { return values[i] != 0 })
+	case MergeMedian:
+		result, err = stats.Median(values)
+	case MergeMin:
+		result = slices.Min(values)
+	case MergeMax:
+		result = slices.Max(values)
+	case MergeMode:
+		result = values[merger.mode(len(values), func(i, j int) bool { return values[i] == values[j] }, allAnswers)]
+	case MergeReliable:
+		result = values[merger.mostReliable(allAnswers)]
+	}
+
+	if err != nil {
+		LogErrorf(err, "Error merging %v for %v", values, field)
+	}
+
+	return result
+}
+
+// Combines boolean answers to a field
+func (merger *fieldMerger) Bool(field string, values []bool, fallback MergeStrategy) bool {
+	if len(values) == 0 {
+		return false
+	}
+
+	switch merger.strategy(field, boolStrategies, fallback) {
+	case MergeAll:
+		return !slices.Contains(values, false)
+	case MergeMode:
+		return values[merger.mode(len(values), func(i, j int) bool { return values[i] == values[j] }, allAnswers)]
+	case MergeReliable:
+		return values[merger.mostReliable(allAnswers)]
+	default:
+		return slices.Contains(values, true)
+	}
+}
+
+// Combines text answers to a field, ignoring blank and unselected ones.
+// Also returns if the scouters who answered disagreed.
+func (merger *fieldMerger) String(field string, values []string, fallback MergeStrategy) (string, bool) {
+	answered := func(i int) bool { return values[i] != "" && values[i] != "Select" }
+
+	var answers []string
+	for i := range values {
+		if answered(i) && !slices.Contains(answers, values[i]) {
+			answers = append(answers, values[i])
+		}
+	}
+	mismatch := len(answers) > 1
+
+	if len(answers) == 0 {
+		return "", false
+	}
+
+	switch merger.strategy(field, stringStrategies, fallback) {
+	case MergeReliable:
+		return values[merger.mostReliable(answered)], mismatch
+	default:
+		return values[merger.mode(len(values), func(i, j int) bool { return values[i] == values[j] }, answered)], mismatch
+	}
+}
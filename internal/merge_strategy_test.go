@@ -0,0 +1,104 @@
+package internal
+
+import "testing"
+
+// Builds a fieldMerger without looking anyone up in users.db
+func testMerger(configured map[string]string, weights ...float64) *fieldMerger {
+	merger := &fieldMerger{configured: configured, weights: weights}
+	for range weights {
+		merger.scouters = append(merger.scouters, "")
+	}
+	return merger
+}
+
+func TestMergeNumberStrategies(t *testing.T) {
+	values := []float64{0, 4, 4, 8}
+	cases := map[MergeStrategy]float64{
+		MergeMean:        4,
+		MergeNonzeroMean: 16.0 / 3,
+		MergeMedian:      4,
+		MergeMin:         0,
+		MergeMax:         8,
+		MergeMode:        4,
+		MergeReliable:    8,
+	}
+
+	for strategy, want := range cases {
+		merger := testMerger(map[string]string{"auto.scores": string(strategy)}, 0.5, 0.6, 0.7, 0.9)
+		if got := merger.Number("auto.scores", values, MergeMean); got != want {
+			t.Errorf("%v of %v = %v, want %v", strategy, values, got, want)
+		}
+	}
+}
+
+func TestMergeFallsBackOnUnusableStrategy(t *testing.T) {
+	merger := testMerger(map[string]string{"auto.scores": string(MergeAny), "auto.can": string(MergeMedian)}, 1, 1)
+
+	if got := merger.Number("auto.scores", []float64{2, 6}, MergeMax); got != 6 {
+		t.Errorf("number merged to %v, want the fallback max of 6", got)
+	}
+	if got := merger.Bool("auto.can", []bool{true, false}, MergeAll); got {
+		t.Error("bool merged to true, want the fallback all")
+	}
+}
+
+func TestMergeBoolStrategies(t *testing.T) {
+	values := []bool{true, false, false}
+	cases := map[MergeStrategy]bool{
+		MergeAny:      true,
+		MergeAll:      false,
+		MergeMode:     false,
+		MergeReliable: true,
+	}
+
+	for strategy, want := range cases {
+		merger := testMerger(map[string]string{"endgame.parked": string(strategy)}, 0.9, 0.5, 0.5)
+		if got := merger.Bool("endgame.parked", values, MergeAny); got != want {
+			t.Errorf("%v of %v = %v, want %v", strategy, values, got, want)
+		}
+	}
+}
+
+func TestMergeStringIgnoresUnanswered(t *testing.T) {
+	merger := testMerger(nil, 1, 1, 1, 1)
+
+	got, mismatch := merger.String("endgame.status", []string{"", "Select", "Deep", "Deep"}, MergeMode)
+	if got != "Deep" || mismatch {
+		t.Errorf("got %q, mismatch %v, want Deep without a mismatch", got, mismatch)
+	}
+
+	got, mismatch = merger.String("endgame.status", []string{"Shallow", "", "Deep", "Select"}, MergeMode)
+	if got != "Shallow" || !mismatch {
+		t.Errorf("got %q, mismatch %v, want the first answer Shallow with a mismatch", got, mismatch)
+	}
+
+	if got, _ := merger.String("endgame.status", []string{"", "Select"}, MergeMode); got != "" {
+		t.Errorf("got %q from unanswered fields", got)
+	}
+}
+
+func TestMergeModeTiesGoToFirstAnswer(t *testing.T) {
+	merger := testMerger(nil, 1, 1, 1, 1)
+	if got := merger.Number("teleop.scores", []float64{3, 5, 5, 3}, MergeMode); got != 3 {
+		t.Errorf("tied mode = %v, want the first answer 3", got)
+	}
+}
+
+func TestMergeStrategyPathsExist(t *testing.T) {
+	// Every field the seasons merge by name should be one the config can point at
+	for year, fields := range map[int][]string{
+		2025: {"Auto.Scores", "Auto.Can", "Endgame.Time", "Endgame.Parking Status", "Pickup Locations.Coral Ground"},
+		2026: {"auto.scores", "auto.accuracy.hpAccuracy", "teleop.botType", "endgame.climbTimer", "issues.disconnect"},
+	} {
+		season, ok := GetSeason(year)
+		if !ok {
+			t.Fatalf("no %v season", year)
+		}
+		flattened := flattenEntry(season.NewMatchEntry())
+		for _, field := range fields {
+			if _, known := flattened[field]; !known {
+				t.Errorf("%v entries have no %v", year, field)
+			}
+		}
+	}
+}
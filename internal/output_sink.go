@@ -0,0 +1,306 @@
+package internal
+
+// Everywhere processed scouting data is written to. Google Sheets is one output, but an event without Google access can use the others.
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// The tabs processed data is written to
+const (
+	TabRawData     = "RawData"
+	TabPitScouting = "PitScouting"
+	TabPrescouting = "Prescouting"
+	TabCoverage    = "Coverage"
+)
+
+// Output sink type enum
+const (
+	SinkSheets  = "sheets"  // The google sheet from SpreadSheetID
+	SinkCSV     = "csv"     // One csv file per tab
+	SinkSqlite  = "sqlite"  // A table in a local sqlite database
+	SinkWebhook = "webhook" // JSON POSTed to a URL
+)
+
+// The configuration of one output sink
+type OutputSinkConfig struct {
+	Type string `yaml:"Type"` // sheets, csv, sqlite or webhook
+	Name string `yaml:"Name"` // What it shows up as in logs and /outputSinks. Defaults to its type.
+	Path string `yaml:"Path"` // The directory for csv files, or the database file for sqlite. Defaults to somewhere in the runtime directory.
+	URL  string `yaml:"URL"`  // Where a webhook POSTs to
+}
+
+// One row of processed data
+type SinkRow struct {
+	Row    int           `json:"Row"`    // The row number, counting from 1 like the sheet. Row 1 is the header.
+	Values []interface{} `json:"Values"` // The cells of the row, starting from column B
+}
+
+// Somewhere processed data can be written to
+type OutputSink interface {
+	// Writes over the passed in rows of a tab
+	WriteRows(tab string, rows []SinkRow) error
+	// Adds rows after the last one with data. From is the row the data starts at, for sinks that have to look for where it ends.
+	AppendRows(tab string, from int, values [][]interface{}) error
+	// Clears everything in the passed in tabs but their headers
+	ClearTabs(tabs []string) error
+}
+
+// Sinks that write in the background, telling how it went themselves instead of through what their methods return
+type backgroundSink interface {
+	// Sets what is told how every background write went
+	reportTo(report func(err error))
+	// Blocks until everything queued has been written, returning the last error if any write failed
+	Flush() error
+	// Gets how many writes are waiting to be made
+	Pending() int
+}
+
+// How writing to one output sink has been going
+type SinkStatus struct {
+	Name        string    `json:"Name"`        // The name of the sink
+	Type        string    `json:"Type"`        // The type of the sink
+	Writes      int       `json:"Writes"`      // How many writes have worked
+	Failures    int       `json:"Failures"`    // How many writes haven't
+	LastSuccess time.Time `json:"LastSuccess"` // When a write last worked
+	LastFailure time.Time `json:"LastFailure"` // When a write last failed
+	LastError   string    `json:"LastError"`   // Why it last failed
+	Stale       bool      `json:"Stale"`       // If it has missed a write since it was last rebuilt
+	Pending     int       `json:"Pending"`     // How many writes are waiting to be made, for sinks that write in the background
+}
+
+// An output sink along with how it has been going
+type registeredSink struct {
+	sink   OutputSink
+	status SinkStatus
+}
+
+// Every configured output sink, held in memory
+var outputSinks struct {
+	mutex sync.Mutex
+	sinks []*registeredSink
+}
+
+// Creates every configured output sink, defaulting to just google sheets if none are configured
+func InitOutputSinks() {
+	configs := CachedConfigs.OutputSinks
+	if len(configs) == 0 {
+		configs = []OutputSinkConfig{{Type: SinkSheets}}
+	}
+
+	var sinks []*registeredSink
+	for _, config := range configs {
+		if config.Name == "" {
+			config.Name = config.Type
+		}
+
+		sink, err := newOutputSink(config)
+		if err != nil {
+			LogErrorf(err, "Problem setting up output sink %v, it will be skipped", config.Name)
+			continue
+		}
+
+		registered := &registeredSink{sink: sink, status: SinkStatus{Name: config.Name, Type: config.Type}}
+		loadSinkStatus(&registered.status)
+		if background, ok := sink.(backgroundSink); ok {
+			background.reportTo(func(err error) { recordSinkResult(registered, err, false) })
+		}
+		sinks = append(sinks, registered)
+		LogMessagef("Writing processed data to %v (%v)", config.Name, config.Type)
+	}
+
+	outputSinks.mutex.Lock()
+	outputSinks.sinks = sinks
+	outputSinks.mutex.Unlock()
+}
+
+// Creates an output sink from its configuration
+func newOutputSink(config OutputSinkConfig) (OutputSink, error) {
+	switch config.Type {
+	case SinkSheets:
+		if Srv == nil {
+			return nil, errors.New("the sheets API isn't set up")
+		}
+		return newSheetsSink(), nil
+	case SinkCSV:
+		if config.Path == "" {
+			config.Path = filepath.Join(CachedConfigs.RuntimeDirectory, "output", config.Name)
+		}
+		return newCSVSink(config.Path)
+	case SinkSqlite:
+		if config.Path == "" {
+			config.Path = filepath.Join(CachedConfigs.PathToDatabases, config.Name+".db")
+		}
+		return newSqliteSink(config.Path)
+	case SinkWebhook:
+		if config.URL == "" {
+			return nil, errors.New("webhooks need a URL")
+		}
+		return newWebhookSink(config.URL), nil
+	}
+
+	return nil, errors.New("unknown output sink type " + config.Type)
+}
+
+// Returns if the config writes to google sheets, including by default
+func UsesSheetsSink(configs GeneralConfigs) bool {
+	if len(configs.OutputSinks) == 0 {
+		return true
+	}
+	for _, config := range configs.OutputSinks {
+		if config.Type == SinkSheets {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the sinks currently configured
+func currentSinks() []*registeredSink {
+	outputSinks.mutex.Lock()
+	defer outputSinks.mutex.Unlock()
+	return outputSinks.sinks
+}
+
+// Runs the passed in write against every output sink, keeping track of how each one went.
+// Sinks that fail are marked stale, but the write only fails if none of them worked.
+// Sinks that write in the background count the write as done once it's queued, so ingestion never waits on them.
+// They keep track of how it went when it's sent, and if it never makes it the sink is marked stale until a reconciliation or rebuild catches it up.
+func writeToSinks(write func(sink OutputSink) error) error {
+	sinks := currentSinks()
+	if len(sinks) == 0 {
+		return errors.New("no output sinks are set up")
+	}
+
+	var errs []error
+	for _, registered := range sinks {
+		err := write(registered.sink)
+		if _, background := registered.sink.(backgroundSink); !background || err != nil {
+			recordSinkResult(registered, err, false)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", registered.status.Name, err))
+		}
+	}
+
+	if len(errs) == len(sinks) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Writes over rows of a tab in every output sink
+func WriteSinkRows(tab string, rows []SinkRow) error {
+	return writeToSinks(func(sink OutputSink) error {
+		return sink.WriteRows(tab, rows)
+	})
+}
+
+// Adds rows after the last one with data in a tab of every output sink
+func AppendSinkRows(tab string, from int, values [][]interface{}) error {
+	return writeToSinks(func(sink OutputSink) error {
+		return sink.AppendRows(tab, from, values)
+	})
+}
+
+// Keeps track of how a write to a sink went. A rebuild that works means the sink is caught up.
+func recordSinkResult(registered *registeredSink, err error, rebuilt bool) {
+	outputSinks.mutex.Lock()
+	status := &registered.status
+	if err == nil {
+		status.Writes++
+		status.LastSuccess = time.Now()
+		if rebuilt {
+			status.Stale = false
+		}
+	} else {
+		status.Failures++
+		status.LastFailure = time.Now()
+		status.LastError = err.Error()
+		status.Stale = true
+	}
+	saved := *status
+	outputSinks.mutex.Unlock()
+
+	if err != nil {
+		LogErrorf(err, "Unable to write to output sink %v", saved.Name)
+	}
+	saveSinkStatus(saved)
+}
+
+// Returns if a sink has missed a write since it was last rebuilt
+func sinkIsStale(registered *registeredSink) bool {
+	outputSinks.mutex.Lock()
+	defer outputSinks.mutex.Unlock()
+	return registered.status.Stale
+}
+
+// Gets how writing to every output sink has been going
+func GetSinkStatuses() []SinkStatus {
+	outputSinks.mutex.Lock()
+	defer outputSinks.mutex.Unlock()
+
+	statuses := []SinkStatus{}
+	for _, registered := range outputSinks.sinks {
+		status := registered.status
+		if background, ok := registered.sink.(backgroundSink); ok {
+			status.Pending = background.Pending()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Waits for every sink that writes in the background to finish what it has queued, used when shutting down
+func FlushOutputSinks() {
+	for _, registered := range currentSinks() {
+		if background, ok := registered.sink.(backgroundSink); ok {
+			if err := background.Flush(); err != nil {
+				LogErrorf(err, "Problem flushing output sink %v", registered.status.Name)
+			}
+		}
+	}
+}
+
+// Fills in the status of a sink from the last time the server ran
+func loadSinkStatus(status *SinkStatus) {
+	var lastSuccess, lastFailure int64
+	err := matchDB.QueryRow("select writes, failures, lastsuccess, lastfailure, lasterror, stale from sink_status where name = ?", status.Name).
+		Scan(&status.Writes, &status.Failures, &lastSuccess, &lastFailure, &status.LastError, &status.Stale)
+	if err != nil {
+		return
+	}
+
+	if lastSuccess != 0 {
+		status.LastSuccess = time.UnixMilli(lastSuccess)
+	}
+	if lastFailure != 0 {
+		status.LastFailure = time.UnixMilli(lastFailure)
+	}
+}
+
+// Saves the status of a sink so it survives restarts, mainly so stale sinks stay stale until they're rebuilt
+func saveSinkStatus(status SinkStatus) {
+	var lastSuccess, lastFailure int64
+	if !status.LastSuccess.IsZero() {
+		lastSuccess = status.LastSuccess.UnixMilli()
+	}
+	if !status.LastFailure.IsZero() {
+		lastFailure = status.LastFailure.UnixMilli()
+	}
+
+	_, err := matchDB.Exec(
+		`insert into sink_status(name, type, writes, failures, lastsuccess, lastfailure, lasterror, stale) values(?, ?, ?, ?, ?, ?, ?, ?)
+		on conflict(name) do update set
+			type = excluded.type, writes = excluded.writes, failures = excluded.failures, lastsuccess = excluded.lastsuccess,
+			lastfailure = excluded.lastfailure, lasterror = excluded.lasterror, stale = excluded.stale`,
+		status.Name, status.Type, status.Writes, status.Failures, lastSuccess, lastFailure, status.LastError, status.Stale,
+	)
+	if err != nil {
+		LogErrorf(err, "Problem saving status of output sink %v", status.Name)
+	}
+}
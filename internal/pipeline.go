@@ -0,0 +1,378 @@
+package internal
+
+// Browsing and moving entries between the directories of the JSON pipeline, with every action recorded
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The directories of the pipeline, as they are named in the admin API
+const (
+	PipelineIn         = "in"
+	PipelineWritten    = "written"
+	PipelinePitWritten = "pitwritten"
+	PipelinePrescouted = "prescouted"
+	PipelineErrored    = "errored"
+	PipelineDiscarded  = "discarded"
+	PipelineArchive    = "archive"
+)
+
+// The actions that can be taken on an entry
+const (
+	PipelineRequeue = "requeue" // Errored -> In
+	PipelineDiscard = "discard" // In, Written, PitWritten, Prescouted or Errored -> Discarded
+	PipelineRestore = "restore" // Discarded -> In
+)
+
+// Gets the directory behind a pipeline name, returning false if there isn't one
+func pipelineDirectory(name string) (string, bool) {
+	switch name {
+	case PipelineIn:
+		return JsonInDirectory, true
+	case PipelineWritten:
+		return JsonWrittenDirectory, true
+	case PipelinePitWritten:
+		return JsonPitWrittenDirectory, true
+	case PipelinePrescouted:
+		return JsonPrescoutedDirectory, true
+	case PipelineErrored:
+		return JsonErroredDirectory, true
+	case PipelineDiscarded:
+		return JsonDiscardedDirectory, true
+	case PipelineArchive:
+		return JsonArchiveDirectory, true
+	}
+	return "", false
+}
+
+// One file somewhere in the pipeline
+type PipelineEntry struct {
+	File      string `json:"File"`      // The name of the file. Archived files are prefixed with their event's folder.
+	Directory string `json:"Directory"` // The pipeline directory it is in
+	Pit       bool   `json:"Pit"`       // If it is a pit scouting entry
+	Event     string `json:"Event"`     // The event key it was submitted under
+	Match     uint   `json:"Match"`     // The match number, 0 for pit scouting
+	Station   string `json:"Station"`   // The driverstation (red1..blue3), empty for pit scouting
+	Team      int    `json:"Team"`      // The team scouted, 0 if the file couldn't be parsed
+	Scouter   string `json:"Scouter"`   // Who scouted it, empty if the file couldn't be parsed
+	Received  int64  `json:"Received"`  // When it was submitted, in unix milliseconds
+	Parsed    bool   `json:"Parsed"`    // If the file could be parsed
+}
+
+// Filters for listing pipeline entries. Zero values match everything.
+type PipelineFilter struct {
+	Directory string
+	Event     string
+	Match     uint
+	Team      int
+	Scouter   string
+}
+
+// Returns if an entry passes the filter
+func (filter PipelineFilter) matches(entry PipelineEntry) bool {
+	return (filter.Event == "" || strings.EqualFold(filter.Event, entry.Event)) &&
+		(filter.Match == 0 || filter.Match == entry.Match) &&
+		(filter.Team == 0 || filter.Team == entry.Team) &&
+		(filter.Scouter == "" || strings.EqualFold(filter.Scouter, entry.Scouter))
+}
+
+// Lists every entry in the pipeline passing the filter, newest first.
+// Every directory is listed if the filter doesn't name one.
+func ListPipelineEntries(filter PipelineFilter) []PipelineEntry {
+	entries := []PipelineEntry{}
+
+	directories := []string{PipelineIn, PipelineWritten, PipelinePitWritten, PipelinePrescouted, PipelineErrored, PipelineDiscarded, PipelineArchive}
+	if filter.Directory != "" {
+		directories = []string{filter.Directory}
+	}
+
+	for _, name := range directories {
+		for _, file := range pipelineFiles(name) {
+			entry := describePipelineFile(name, file)
+			if filter.matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Received > entries[j].Received
+	})
+
+	return entries
+}
+
+// Gets the names of every json file in a pipeline directory. Archive is one folder per event, so its files are prefixed with that folder.
+func pipelineFiles(name string) []string {
+	var files []string
+
+	directory, ok := pipelineDirectory(name)
+	if !ok {
+		return files
+	}
+
+	listed, readErr := os.ReadDir(directory)
+	if readErr != nil {
+		LogErrorf(readErr, "Problem reading %v", directory)
+		return files
+	}
+
+	for _, file := range listed {
+		if file.IsDir() && name == PipelineArchive {
+			archived, archiveErr := os.ReadDir(filepath.Join(directory, file.Name()))
+			if archiveErr != nil {
+				LogErrorf(archiveErr, "Problem reading %v", filepath.Join(directory, file.Name()))
+				continue
+			}
+			for _, archivedFile := range archived {
+				if strings.HasSuffix(archivedFile.Name(), ".json") {
+					files = append(files, filepath.Join(file.Name(), archivedFile.Name()))
+				}
+			}
+		} else if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			files = append(files, file.Name())
+		}
+	}
+
+	return files
+}
+
+// Builds the PipelineEntry of a file from its name and contents
+func describePipelineFile(directory string, file string) PipelineEntry {
+	baseName := filepath.Base(file)
+	split := strings.Split(strings.TrimSuffix(baseName, ".json"), "_")
+
+	entry := PipelineEntry{
+		File:      file,
+		Directory: directory,
+		Pit:       isPitFile(baseName),
+		Event:     eventFromFileName(baseName),
+		Received:  receivedFromFileName(baseName).UnixMilli(),
+	}
+
+	if entry.Pit && len(split) >= 2 {
+		team, _ := strconv.Atoi(split[1])
+		entry.Team = team
+	} else if len(split) >= 3 {
+		match, _ := strconv.ParseUint(split[1], 10, 64)
+		entry.Match = uint(match)
+		entry.Station = split[2]
+	}
+
+	data, ok := readPipelineFile(directory, file)
+	if !ok {
+		return entry
+	}
+
+	season := SeasonForEvent(entry.Event)
+	if entry.Pit {
+		if pit, decodeErr := season.DecodePit(data); decodeErr == nil {
+			entry.Team = pit.Team()
+			entry.Scouter = pit.ScoutedBy()
+			entry.Parsed = true
+		}
+	} else if team, decodeErr := season.DecodeMatch(data); decodeErr == nil {
+		info := team.Info()
+		entry.Team = int(info.TeamNumber)
+		entry.Scouter = info.Scouter
+		entry.Parsed = true
+	}
+
+	return entry
+}
+
+// Reads a file in a pipeline directory, making sure the name doesn't escape it
+func readPipelineFile(directory string, file string) ([]byte, bool) {
+	dirPath, ok := pipelineDirectory(directory)
+	if !ok || file == "" || strings.Contains(file, "..") || filepath.IsAbs(file) {
+		return nil, false
+	}
+
+	data, readErr := os.ReadFile(filepath.Join(dirPath, file))
+	return data, readErr == nil
+}
+
+// A pipeline entry along with everything known about it
+type PipelineEntryDetails struct {
+	Entry      PipelineEntry     `json:"Entry"`                // Where the entry is
+	Data       json.RawMessage   `json:"Data,omitempty"`       // The entry as submitted
+	DeadLetter *DeadLetterRecord `json:"DeadLetter,omitempty"` // Why it was dead-lettered, if it is in Errored
+	Warnings   []FieldError      `json:"Warnings,omitempty"`   // How it didn't match the schedule or team list when it was submitted
+	History    []PipelineAction  `json:"History"`              // Every action taken on it
+}
+
+// Gets the details of one file in the pipeline, returning false if it doesn't exist
+func GetPipelineEntry(directory string, file string) (PipelineEntryDetails, bool) {
+	var details PipelineEntryDetails
+
+	data, ok := readPipelineFile(directory, file)
+	if !ok {
+		return details, false
+	}
+
+	details.Entry = describePipelineFile(directory, file)
+	if json.Valid(data) {
+		details.Data = data
+	}
+
+	if directory == PipelineErrored {
+		if record, found := GetDeadLetterRecord(file); found {
+			details.DeadLetter = &record
+		}
+	}
+
+	details.Warnings = GetEntryWarnings(filepath.Base(file))
+	details.History = GetPipelineActions(filepath.Base(file))
+
+	return details, true
+}
+
+// Moves an entry between pipeline directories, recording who did it.
+// Returns the directory it was moved to.
+func MovePipelineEntry(action string, directory string, file string, admin string, note string) (string, error) {
+	if file == "" || file != filepath.Base(file) {
+		return "", errors.New("invalid file name " + file)
+	}
+
+	var to string
+	switch action {
+	case PipelineRequeue:
+		if directory != PipelineErrored {
+			return "", errors.New("only errored entries can be requeued")
+		}
+		to = PipelineIn
+	case PipelineDiscard:
+		if directory != PipelineIn && directory != PipelineWritten && directory != PipelinePitWritten && directory != PipelinePrescouted && directory != PipelineErrored {
+			return "", errors.New("entries can't be discarded from " + directory)
+		}
+		to = PipelineDiscarded
+	case PipelineRestore:
+		if directory != PipelineDiscarded {
+			return "", errors.New("only discarded entries can be restored")
+		}
+		to = PipelineIn
+	default:
+		return "", errors.New("unknown action " + action)
+	}
+
+	fromPath, _ := pipelineDirectory(directory)
+	toPath, _ := pipelineDirectory(to)
+
+	// Keep ingestion workers off the match slot while the file moves
+	key := slotKey(file)
+	lockSlot(key)
+	defer unlockSlot(key)
+
+	if _, statErr := os.Stat(filepath.Join(fromPath, file)); statErr != nil {
+		return "", errors.New(file + " is not in " + directory)
+	}
+
+	if to == PipelineIn {
+		data, readErr := os.ReadFile(filepath.Join(fromPath, file))
+		if readErr != nil {
+			return "", readErr
+		}
+
+		tempFile, createErr := createTempFile(filepath.Join(JsonInDirectory, file))
+		if createErr != nil {
+			return "", createErr
+		}
+		if !writeSubmission(file, tempFile, data) {
+			return "", errors.New("unable to write " + file + " to In")
+		}
+
+		if removeErr := os.Remove(filepath.Join(fromPath, file)); removeErr != nil {
+			LogErrorf(removeErr, "Problem removing %v", filepath.Join(fromPath, file))
+		}
+
+		SetEntryState(file, EntryQueued)
+		clearRetryState(file)
+	} else {
+		if !MoveFile(filepath.Join(fromPath, file), filepath.Join(toPath, file)) {
+			return "", errors.New("unable to move " + file + " to " + to)
+		}
+
+		SetEntryState(file, EntryDiscarded)
+	}
+
+	if directory == PipelineErrored {
+		sidecar := filepath.Join(JsonErroredDirectory, file+DeadLetterExtension)
+		if removeErr := os.Remove(sidecar); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			LogErrorf(removeErr, "Problem removing %v", sidecar)
+		}
+	}
+
+	RecordPipelineAction(PipelineAction{
+		Time:   time.Now(),
+		Admin:  admin,
+		Action: action,
+		File:   file,
+		From:   directory,
+		To:     to,
+		Note:   note,
+	})
+
+	LogMessagef("%v moved %v from %v to %v (%v)", admin, file, directory, to, action)
+
+	return to, nil
+}
+
+// One action taken on the pipeline by an admin
+type PipelineAction struct {
+	Time   time.Time `json:"Time"`   // When it was taken
+	Admin  string    `json:"Admin"`  // Who took it
+	Action string    `json:"Action"` // What was done
+	File   string    `json:"File"`   // The file it was done to
+	From   string    `json:"From"`   // The directory the file was in
+	To     string    `json:"To"`     // The directory the file was moved to
+	Note   string    `json:"Note"`   // Why, if they said
+}
+
+// Records an action taken on the pipeline in matches.db
+func RecordPipelineAction(action PipelineAction) {
+	_, err := matchDB.Exec("insert into pipeline_audit(time, admin, action, file, fromdir, todir, note) values(?, ?, ?, ?, ?, ?, ?)",
+		action.Time.UnixMilli(), action.Admin, action.Action, action.File, action.From, action.To, action.Note)
+	if err != nil {
+		LogErrorf(err, "Problem recording pipeline action %v", action)
+	}
+}
+
+// Gets the recorded pipeline actions, newest first. Passing in a file name only gets the actions taken on that file.
+func GetPipelineActions(file string) []PipelineAction {
+	actions := []PipelineAction{}
+
+	query := "select time, admin, action, file, fromdir, todir, note from pipeline_audit"
+	var args []any
+	if file != "" {
+		query += " where file = ?"
+		args = append(args, file)
+	}
+	query += " order by time desc, id desc"
+
+	rows, err := matchDB.Query(query, args...)
+	if err != nil {
+		LogErrorf(err, "Problem executing sql query %v", query)
+		return actions
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var action PipelineAction
+		var millis int64
+		if scanErr := rows.Scan(&millis, &action.Admin, &action.Action, &action.File, &action.From, &action.To, &action.Note); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to pipeline audit query")
+			continue
+		}
+		action.Time = time.UnixMilli(millis)
+		actions = append(actions, action)
+	}
+
+	return actions
+}
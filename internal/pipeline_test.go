@@ -0,0 +1,25 @@
+package internal
+
+import "testing"
+
+func TestDescribePipelineFileNames(t *testing.T) {
+	setupTestEnvironment(t)
+
+	cases := []struct {
+		file  string
+		pit   bool
+		team  int
+		match uint
+	}{
+		{"2026test_1234_1700000000000.json", true, 1234, 0},
+		{"2026test_12_red1_1700000000000.json", false, 0, 12},
+		{"stray.json", true, 0, 0},
+	}
+
+	for _, testCase := range cases {
+		entry := describePipelineFile(JsonInDirectory, testCase.file)
+		if entry.Pit != testCase.pit || entry.Team != testCase.team || entry.Match != testCase.match {
+			t.Errorf("describePipelineFile(%v) = %+v", testCase.file, entry)
+		}
+	}
+}
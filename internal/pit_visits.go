@@ -0,0 +1,122 @@
+package internal
+
+// Teams get pit scouted more than once over an event, so every visit is kept and merged onto the team's one PitScouting row
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Every visit to a team's pit, along with what they merge to
+type PitHistory struct {
+	Event  string           `json:"Event"`  // The event key
+	Team   int              `json:"Team"`   // The team number
+	Visits []StoredPitEntry `json:"Visits"` // Every written visit, oldest first
+	Merged PitEntry         `json:"Merged"` // What is on the sheet
+}
+
+// Merges visits to the same pit, oldest first, into one entry. Every answer comes from the newest visit that gave one,
+// so a quick second visit to ask one question doesn't blank out the rest.
+// Empty answers are the ones the apps send for questions nobody filled in: empty text, empty lists and null.
+// False and 0 are real answers, so a later visit can correct a yes to a no.
+func MergePitVisits(season *GameSeason, visits []PitEntry) (PitEntry, error) {
+	if len(visits) == 1 {
+		return visits[0], nil
+	}
+
+	merged := make(map[string]any)
+	for _, visit := range visits {
+		visitBytes, marshalErr := json.Marshal(visit)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+
+		var answers map[string]any
+		if unmarshalErr := json.Unmarshal(visitBytes, &answers); unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+		mergeAnswers(merged, answers)
+	}
+
+	mergedBytes, marshalErr := json.Marshal(merged)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return season.DecodePit(mergedBytes)
+}
+
+// Copies every non-empty answer of a newer visit over the merged answers so far, going into nested sections
+func mergeAnswers(merged map[string]any, newer map[string]any) {
+	for key, value := range newer {
+		section, isSection := value.(map[string]any)
+		mergedSection, wasSection := merged[key].(map[string]any)
+		if isSection && wasSection {
+			mergeAnswers(mergedSection, section)
+			continue
+		}
+
+		if _, exists := merged[key]; !exists || !isEmptyAnswer(value) {
+			merged[key] = value
+		}
+	}
+}
+
+// Returns if a decoded json value is what the apps send when a question is left alone
+func isEmptyAnswer(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case []any:
+		return len(typed) == 0
+	}
+	return false
+}
+
+// Merges every written visit to a team's pit, plus one that is being written if it isn't empty
+func mergedTeamPit(season *GameSeason, event string, team int, pending StoredPitEntry) (PitEntry, error) {
+	visits, err := GetPitVisits(event, team)
+	if err != nil {
+		return nil, err
+	}
+	if pending.Data != nil {
+		visits = slices.DeleteFunc(visits, func(visit StoredPitEntry) bool { return visit.File == pending.File })
+		visits = append(visits, pending)
+		slices.SortStableFunc(visits, func(a, b StoredPitEntry) int { return a.Received.Compare(b.Received) })
+	}
+
+	var entries []PitEntry
+	for _, visit := range visits {
+		entries = append(entries, visit.Data)
+	}
+
+	return MergePitVisits(season, entries)
+}
+
+// Gets the history of a team's pit at an event
+func GetPitHistory(event string, team int) (PitHistory, error) {
+	visits, err := GetPitVisits(event, team)
+	history := PitHistory{Event: event, Team: team, Visits: visits}
+	if err != nil {
+		return history, err
+	}
+	if history.Visits == nil {
+		history.Visits = []StoredPitEntry{}
+		return history, nil
+	}
+
+	var entries []PitEntry
+	for _, visit := range history.Visits {
+		entries = append(entries, visit.Data)
+	}
+
+	merged, err := MergePitVisits(SeasonForEvent(event), entries)
+	history.Merged = merged
+	return history, err
+}
+
+// Builds the stored form of a pit entry that hasn't been written yet
+func pendingPitVisit(fileName string, pit PitEntry) StoredPitEntry {
+	return StoredPitEntry{File: fileName, Event: eventFromFileName(fileName), State: EntryQueued, Received: receivedFromFileName(fileName), Data: pit}
+}
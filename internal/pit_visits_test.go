@@ -0,0 +1,73 @@
+package internal
+
+import "testing"
+
+func TestMergePitVisits(t *testing.T) {
+	season := SeasonForEvent("2025test")
+
+	first := &PitScoutingData{TeamNumber: 1816, Scouter: "a", Weight: "120", Drivetrain: "Swerve", Deep: true, Cycle: 3}
+	first.Coral.L2 = true
+	second := &PitScoutingData{TeamNumber: 1816, Scouter: "b", Weight: "125", Notes: "Fixed their climber"}
+	second.Coral.L4 = true
+
+	merged, err := MergePitVisits(season, []PitEntry{first, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pit, ok := merged.(*PitScoutingData)
+	if !ok {
+		t.Fatalf("merged into a %T", merged)
+	}
+
+	if pit.Scouter != "b" || pit.Weight != "125" || pit.Notes != "Fixed their climber" {
+		t.Errorf("newest answers not kept: %+v", pit)
+	}
+	if pit.Drivetrain != "Swerve" {
+		t.Errorf("older answers blanked by an empty newer visit: %+v", pit)
+	}
+	if pit.Coral.L2 || !pit.Coral.L4 {
+		t.Errorf("nested answers not merged: %+v", pit.Coral)
+	}
+}
+
+func TestMergePitVisitsCorrectsAnswers(t *testing.T) {
+	first := &PitScoutingData{TeamNumber: 1816, Deep: true, Cycle: 3}
+	second := &PitScoutingData{TeamNumber: 1816, Deep: false, Cycle: 0}
+
+	merged, err := MergePitVisits(SeasonForEvent("2025test"), []PitEntry{first, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pit := merged.(*PitScoutingData)
+	if pit.Deep || pit.Cycle != 0 {
+		t.Errorf("a newer visit couldn't correct true to false or 3 to 0: %+v", pit)
+	}
+}
+
+func TestMergeOnePitVisit(t *testing.T) {
+	visit := &PitScoutingData{TeamNumber: 1816, Weight: "120"}
+
+	merged, err := MergePitVisits(SeasonForEvent("2025test"), []PitEntry{visit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != visit {
+		t.Errorf("one visit merged into %+v", merged)
+	}
+}
+
+func TestIsEmptyAnswer(t *testing.T) {
+	empty := []any{nil, "", []any{}}
+	for _, value := range empty {
+		if !isEmptyAnswer(value) {
+			t.Errorf("%#v isn't empty", value)
+		}
+	}
+
+	answered := []any{"Swerve", 3.0, 0.0, true, false, []any{"L4"}, map[string]any{}}
+	for _, value := range answered {
+		if isEmptyAnswer(value) {
+			t.Errorf("%#v is empty", value)
+		}
+	}
+}
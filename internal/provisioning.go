@@ -0,0 +1,210 @@
+package internal
+
+// Setting up the spreadsheet for an event, so nobody has to add tabs and paste in headers by hand before every event
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Held while a spreadsheet is being provisioned, so two changes in a row can't both add the same tabs
+var provisioning sync.Mutex
+
+// Sets up the spreadsheet of the current event: creates any missing tabs, writes the headers, adds the conditional formatting to RawData
+// if it has none, fills in the match numbers from the schedule, and has the Coverage tab refreshed. If NewSheetPerEvent is set, the event gets its own spreadsheet first.
+func ProvisionSpreadsheet() error {
+	provisioning.Lock()
+	defer provisioning.Unlock()
+
+	if Srv == nil {
+		return errors.New("the sheets API isn't set up")
+	}
+
+	event := GetCurrentEvent()
+	if CachedConfigs.NewSheetPerEvent {
+		if err := useEventSpreadsheet(event); err != nil {
+			return err
+		}
+	}
+
+	var spreadsheet *sheets.Spreadsheet
+	if err := callSheets(func() error {
+		var getErr error
+		spreadsheet, getErr = Srv.Spreadsheets.Get(SpreadsheetId).Do()
+		return getErr
+	}); err != nil {
+		return fmt.Errorf("unable to read spreadsheet %v: %w", SpreadsheetId, err)
+	}
+
+	var existing []string
+	formatted := false
+	for _, tab := range spreadsheet.Sheets {
+		existing = append(existing, tab.Properties.Title)
+		if tab.Properties.Title == TabRawData && len(tab.ConditionalFormats) > 0 {
+			formatted = true
+		}
+	}
+
+	var addTabs []*sheets.Request
+	for _, tab := range sheetTabs {
+		if !slices.Contains(existing, tab) {
+			addTabs = append(addTabs, &sheets.Request{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: tab}}})
+			LogMessagef("Adding the %v tab to spreadsheet %v", tab, SpreadsheetId)
+		}
+	}
+	if len(addTabs) > 0 {
+		if err := callSheets(func() error {
+			_, addErr := Srv.Spreadsheets.BatchUpdate(SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{Requests: addTabs}).Do()
+			return addErr
+		}); err != nil {
+			return fmt.Errorf("unable to add tabs: %w", err)
+		}
+	}
+
+	season := CurrentSeason()
+	data := []*sheets.ValueRange{}
+	for _, tab := range sheetTabs {
+		data = append(data, &sheets.ValueRange{Range: tab + "!B1", Values: [][]interface{}{season.Headers(tab)}})
+	}
+	if matchNumbers := scheduledMatchNumbers(); len(matchNumbers) > 0 {
+		data = append(data, &sheets.ValueRange{Range: TabRawData + "!A2", Values: matchNumbers})
+	}
+	if err := callSheets(func() error {
+		_, writeErr := Srv.Spreadsheets.Values.BatchUpdate(SpreadsheetId, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "RAW",
+			Data:             data,
+		}).Do()
+		return writeErr
+	}); err != nil {
+		return fmt.Errorf("unable to write headers and match numbers: %w", err)
+	}
+
+	// Conditional formatting rules stack up instead of replacing each other, so they're only added once
+	if !formatted {
+		WriteConditionalFormatting()
+	}
+
+	MarkCoverageStale()
+	markProvisioned(event, SpreadsheetId)
+	LogMessagef("Provisioned spreadsheet %v for %v", SpreadsheetId, event)
+	return nil
+}
+
+// Provisions the spreadsheet if the event or the spreadsheet changed since it was last provisioned.
+// Does nothing if the sheets API isn't set up, since then nothing is written to google sheets.
+func ProvisionIfChanged() {
+	if Srv == nil {
+		return
+	}
+
+	event := GetCurrentEvent()
+	if CachedConfigs.NewSheetPerEvent {
+		if eventSheet, ok := getEventSpreadsheet(event); !ok || eventSheet != SpreadsheetId {
+			provisionAndLog()
+			return
+		}
+	}
+
+	if !isProvisioned(event, SpreadsheetId) {
+		provisionAndLog()
+	}
+}
+
+// Provisions the spreadsheet, logging if it didn't work
+func provisionAndLog() {
+	if err := ProvisionSpreadsheet(); err != nil {
+		LogError(err, "Problem provisioning the spreadsheet")
+	}
+}
+
+// Switches to the spreadsheet created for an event, creating one if it doesn't have one yet
+func useEventSpreadsheet(event string) error {
+	id, ok := getEventSpreadsheet(event)
+	if !ok {
+		var spreadsheet *sheets.Spreadsheet
+		request := &sheets.Spreadsheet{Properties: &sheets.SpreadsheetProperties{Title: fmt.Sprintf("GreenScout - %v (%v)", CachedConfigs.EventKeyName, event)}}
+		for _, tab := range sheetTabs {
+			request.Sheets = append(request.Sheets, &sheets.Sheet{Properties: &sheets.SheetProperties{Title: tab}})
+		}
+
+		if err := callSheets(func() error {
+			var createErr error
+			spreadsheet, createErr = Srv.Spreadsheets.Create(request).Do()
+			return createErr
+		}); err != nil {
+			return fmt.Errorf("unable to create a spreadsheet for %v: %w", event, err)
+		}
+
+		id = spreadsheet.SpreadsheetId
+		if _, err := matchDB.Exec("insert into sheet_provisioning(event, spreadsheet, created, provisioned) values(?, ?, 1, 0)", event, id); err != nil {
+			LogErrorf(err, "Problem saving spreadsheet %v of %v", id, event)
+		}
+		LogMessagef("Created spreadsheet %v for %v", id, event)
+	}
+
+	if id != SpreadsheetId {
+		if err := saveSheetID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Gets the spreadsheet created for an event, returning false if one hasn't been
+func getEventSpreadsheet(event string) (string, bool) {
+	var id string
+	err := matchDB.QueryRow("select spreadsheet from sheet_provisioning where event = ? and created = 1", event).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		LogErrorf(err, "Problem looking up the spreadsheet of %v", event)
+	}
+	return id, err == nil
+}
+
+// Returns if a spreadsheet has been provisioned for an event
+func isProvisioned(event string, spreadsheet string) bool {
+	var provisioned int64
+	err := matchDB.QueryRow("select provisioned from sheet_provisioning where event = ? and spreadsheet = ?", event, spreadsheet).Scan(&provisioned)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		LogErrorf(err, "Problem checking if %v was provisioned for %v", spreadsheet, event)
+	}
+	return provisioned != 0
+}
+
+// Keeps track of a spreadsheet having been provisioned for an event
+func markProvisioned(event string, spreadsheet string) {
+	_, err := matchDB.Exec(
+		`insert into sheet_provisioning(event, spreadsheet, created, provisioned) values(?, ?, 0, ?)
+		on conflict(event, spreadsheet) do update set provisioned = excluded.provisioned`,
+		event, spreadsheet, time.Now().UnixMilli(),
+	)
+	if err != nil {
+		LogErrorf(err, "Problem saving that %v was provisioned for %v", spreadsheet, event)
+	}
+}
+
+// Builds column A of RawData from the schedule: every match number six times, once per driverstation, starting at row 2
+func scheduledMatchNumbers() [][]interface{} {
+	schedule, ok := GetSchedule()
+	if !ok {
+		return nil
+	}
+
+	var matches uint
+	for match := range schedule {
+		matches = max(matches, match)
+	}
+
+	var values [][]interface{}
+	for match := uint(1); match <= matches; match++ {
+		for range 6 {
+			values = append(values, []interface{}{match})
+		}
+	}
+	return values
+}
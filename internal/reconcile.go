@@ -0,0 +1,240 @@
+package internal
+
+// Checking the sheet against matches.db, since people delete and overwrite rows by accident during events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// How often the sheet is checked by default, in minutes
+const kDefaultReconcileMinutes = 10
+
+// Sheet mismatch kind enum
+const (
+	MismatchMissing = "missing" // The row is empty on the sheet
+	MismatchAltered = "altered" // The row is on the sheet, but not as it was written
+	MismatchExtra   = "extra"   // The row has something on the sheet, but nothing in matches.db goes there
+)
+
+// One row of the sheet that doesn't match matches.db
+type SheetMismatch struct {
+	Tab      string        `json:"Tab"`      // The tab the row is on
+	Row      int           `json:"Row"`      // The row number, counting from 1 like the sheet
+	Problem  string        `json:"Problem"`  // missing, altered or extra
+	Expected []interface{} `json:"Expected"` // What should be on the row, starting from column B
+	Found    []interface{} `json:"Found"`    // What is on the row, starting from column B
+}
+
+// The result of checking the sheet against matches.db
+type ReconcileReport struct {
+	Event       string          `json:"Event"`       // The event key
+	Spreadsheet string          `json:"Spreadsheet"` // The ID of the spreadsheet checked
+	Checked     int             `json:"Checked"`     // How many rows were compared
+	Mismatches  []SheetMismatch `json:"Mismatches"`  // Every row that didn't match
+	Rewritten   bool            `json:"Rewritten"`   // If the rows that didn't match were rewritten
+	Time        time.Time       `json:"Time"`        // When the check was made
+}
+
+// Reads the RawData, PitScouting and Prescouting tabs back and compares every row with what matches.db says should be there.
+// If rewrite is set, every row that didn't match is written over with what should be there, which catches up a stale sheet.
+func ReconcileSheet(rewrite bool) (ReconcileReport, error) {
+	event := GetCurrentEvent()
+	report := ReconcileReport{Event: event, Spreadsheet: SpreadsheetId, Mismatches: []SheetMismatch{}, Time: time.Now()}
+
+	registered := registeredSheetsSink()
+	if Srv == nil || registered == nil {
+		return report, errors.New("nothing is being written to google sheets")
+	}
+
+	// Anything still buffered would look like it's missing
+	sink := registered.sink.(*sheetsSink)
+	if flushErr := sink.Flush(); flushErr != nil {
+		LogError(flushErr, "Problem flushing the sheet before reconciling it")
+	}
+
+	expected, _, err := expectedSheetRows(event)
+	if err != nil {
+		return report, fmt.Errorf("unable to read matches.db: %w", err)
+	}
+
+	var ranges []string
+	for _, tab := range layoutTabs {
+		ranges = append(ranges, tab+"!B1:Z")
+	}
+
+	var response *sheets.BatchGetValuesResponse
+	if err := callSheets(func() error {
+		var getErr error
+		response, getErr = Srv.Spreadsheets.Values.BatchGet(SpreadsheetId).
+			Ranges(ranges...).
+			ValueRenderOption("UNFORMATTED_VALUE").Do()
+		return getErr
+	}); err != nil {
+		return report, fmt.Errorf("unable to read the sheet: %w", err)
+	}
+
+	for i, tab := range layoutTabs {
+		var found [][]interface{}
+		if i < len(response.ValueRanges) {
+			found = response.ValueRanges[i].Values
+		}
+		mismatches, checked := compareTab(tab, expected[tab], found)
+		report.Mismatches = append(report.Mismatches, mismatches...)
+		report.Checked += checked
+	}
+
+	if rewrite && len(report.Mismatches) > 0 {
+		rows := make(map[string][]SinkRow)
+		for _, mismatch := range report.Mismatches {
+			// Anything typed past the end of the row gets blanked too
+			values := slices.Clone(mismatch.Expected)
+			for len(values) < len(mismatch.Found) {
+				values = append(values, "")
+			}
+			rows[mismatch.Tab] = append(rows[mismatch.Tab], SinkRow{Row: mismatch.Row, Values: values})
+		}
+
+		var err error
+		for _, tab := range layoutTabs {
+			if err == nil && len(rows[tab]) > 0 {
+				err = sink.WriteRows(tab, rows[tab])
+			}
+		}
+		if err == nil {
+			err = sink.Flush()
+		}
+		if err != nil {
+			return report, fmt.Errorf("unable to rewrite the rows that didn't match: %w", err)
+		}
+
+		report.Rewritten = true
+		LogMessagef("Rewrote %v rows of the sheet that didn't match matches.db", len(report.Mismatches))
+	}
+
+	if rewrite {
+		recordSinkResult(registered, nil, true)
+	}
+	return report, nil
+}
+
+// Compares what should be on a tab with what was read back from it, returning every row that doesn't match and how many were compared
+func compareTab(tab string, expected []SinkRow, found [][]interface{}) ([]SheetMismatch, int) {
+	var mismatches []SheetMismatch
+	expectedRows := make(map[int]bool)
+	checked := len(expected)
+
+	for _, row := range expected {
+		expectedRows[row.Row] = true
+
+		var foundValues []interface{}
+		if row.Row-1 < len(found) {
+			foundValues = found[row.Row-1]
+		}
+
+		want := cellTexts(row.Values)
+		got := cellTexts(foundValues)
+		if slices.Equal(want, got) {
+			continue
+		}
+
+		problem := MismatchAltered
+		if len(got) == 0 {
+			problem = MismatchMissing
+		}
+		mismatches = append(mismatches, SheetMismatch{Tab: tab, Row: row.Row, Problem: problem, Expected: row.Values, Found: foundValues})
+	}
+
+	for i, values := range found {
+		if expectedRows[i+1] {
+			continue
+		}
+		checked++
+		if len(cellTexts(values)) == 0 {
+			continue
+		}
+
+		// Blanks the row when it's rewritten
+		blank := make([]interface{}, len(values))
+		for j := range blank {
+			blank[j] = ""
+		}
+		mismatches = append(mismatches, SheetMismatch{Tab: tab, Row: i + 1, Problem: MismatchExtra, Expected: blank, Found: values})
+	}
+
+	return mismatches, checked
+}
+
+// Turns a row into how its cells read on the sheet, leaving off empty cells at the end.
+// Rows are sent to the sheet as json, so they're turned into json and back first to compare the same thing.
+func cellTexts(values []interface{}) []string {
+	var decoded []any
+	if encoded, err := json.Marshal(values); err == nil {
+		json.Unmarshal(encoded, &decoded)
+	}
+
+	var texts []string
+	for _, value := range decoded {
+		switch typed := value.(type) {
+		case nil:
+			texts = append(texts, "")
+		case float64:
+			texts = append(texts, strconv.FormatFloat(typed, 'f', -1, 64))
+		case bool:
+			texts = append(texts, fmt.Sprint(typed))
+		case string:
+			texts = append(texts, typed)
+		default:
+			encoded, _ := json.Marshal(typed)
+			texts = append(texts, string(encoded))
+		}
+	}
+
+	for len(texts) > 0 && texts[len(texts)-1] == "" {
+		texts = texts[:len(texts)-1]
+	}
+	return texts
+}
+
+// Gets the registered google sheets sink, or nil if there isn't one
+func registeredSheetsSink() *registeredSink {
+	for _, registered := range currentSinks() {
+		if _, ok := registered.sink.(*sheetsSink); ok {
+			return registered
+		}
+	}
+	return nil
+}
+
+// Checks the sheet against matches.db every ReconcileMinutes, logging anything that doesn't match.
+// If the sheet is stale from writes that never made it, the rows that don't match are rewritten to catch it up.
+// Returns straight away if it's turned off or nothing is written to google sheets.
+func RunSheetReconciliation() {
+	minutes := CachedConfigs.ReconcileMinutes
+	if minutes == 0 {
+		minutes = kDefaultReconcileMinutes
+	}
+	if minutes < 0 || registeredSheetsSink() == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+	for range ticker.C {
+		registered := registeredSheetsSink()
+		report, err := ReconcileSheet(registered != nil && sinkIsStale(registered))
+		if err != nil {
+			LogError(err, "Problem reconciling the sheet")
+			continue
+		}
+
+		if len(report.Mismatches) > 0 && !report.Rewritten {
+			LogMessagef("%v of %v rows of the sheet don't match matches.db. See /reconcileSheet to check and rewrite them.", len(report.Mismatches), report.Checked)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCellTexts(t *testing.T) {
+	cases := []struct {
+		values []interface{}
+		want   []string
+	}{
+		{[]interface{}{1816, uint(3), 2.5, true, "red1"}, []string{"1816", "3", "2.5", "true", "red1"}},
+		{[]interface{}{"a", nil, "", nil}, []string{"a"}},
+		{[]interface{}{"", "b"}, []string{"", "b"}},
+		{[]interface{}{[]int{1, 2}}, []string{"[1,2]"}},
+		{nil, nil},
+	}
+
+	for _, testCase := range cases {
+		if got := cellTexts(testCase.values); !slices.Equal(got, testCase.want) {
+			t.Errorf("cellTexts(%v) = %q, want %q", testCase.values, got, testCase.want)
+		}
+	}
+
+	// Read back unformatted, a whole number comes back as a float
+	if !slices.Equal(cellTexts([]interface{}{12}), cellTexts([]interface{}{12.0})) {
+		t.Error("12 and 12.0 read differently")
+	}
+}
+
+func TestCompareTab(t *testing.T) {
+	expected := []SinkRow{
+		{Row: 1, Values: []interface{}{"Team", "Scouter"}},
+		{Row: 2, Values: []interface{}{1816, "a"}},
+		{Row: 3, Values: []interface{}{254, "b"}},
+		{Row: 4, Values: []interface{}{118, "c"}},
+	}
+	found := [][]interface{}{
+		{"Team", "Scouter"},
+		{1816.0, "a", ""}, // Matches, blank cells at the end don't count
+		{254.0, "typo"},   // Altered
+		{},                // Missing
+		{"", ""},          // Blank, so not extra
+		{"stray"},         // Extra
+	}
+
+	mismatches, checked := compareTab(TabRawData, expected, found)
+	if checked != 6 {
+		t.Errorf("checked %v rows, want 6", checked)
+	}
+
+	var problems []string
+	for _, mismatch := range mismatches {
+		problems = append(problems, mismatch.Problem)
+		if mismatch.Tab != TabRawData {
+			t.Errorf("mismatch on tab %v", mismatch.Tab)
+		}
+	}
+	if want := []string{MismatchAltered, MismatchMissing, MismatchExtra}; !slices.Equal(problems, want) {
+		t.Fatalf("problems %v, want %v", problems, want)
+	}
+
+	if mismatches[0].Row != 3 || mismatches[1].Row != 4 || mismatches[2].Row != 6 {
+		t.Errorf("mismatched rows %v, %v, %v, want 3, 4, 6", mismatches[0].Row, mismatches[1].Row, mismatches[2].Row)
+	}
+	if !slices.Equal(cellTexts(mismatches[2].Expected), nil) || len(mismatches[2].Expected) != 1 {
+		t.Errorf("extra row would be rewritten with %v, want one blank cell", mismatches[2].Expected)
+	}
+}
+
+func TestCompareTabPastEndOfSheet(t *testing.T) {
+	expected := []SinkRow{{Row: 1, Values: []interface{}{"Team"}}, {Row: 5, Values: []interface{}{1816}}}
+
+	mismatches, _ := compareTab(TabPitScouting, expected, [][]interface{}{{"Team"}})
+	if len(mismatches) != 1 || mismatches[0].Row != 5 || mismatches[0].Problem != MismatchMissing {
+		t.Errorf("unexpected mismatches %+v", mismatches)
+	}
+}
+
+func TestEntryRowsAreStable(t *testing.T) {
+	setupTestEnvironment(t)
+
+	for i, file := range []string{"2026test_9_red1_1.json", "2026test_2_blue3_2.json", "2026test_5_red2_3.json"} {
+		row, err := GetEntryRow("2026test", file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row != i+2 {
+			t.Errorf("%v got row %v, want %v", file, row, i+2)
+		}
+	}
+
+	if row, _ := GetEntryRow("2026test", "2026test_9_red1_1.json"); row != 2 {
+		t.Errorf("first entry moved to row %v", row)
+	}
+	if row, _ := GetEntryRow("2026other", "2026other_1_red1_1.json"); row != 2 {
+		t.Errorf("another event's first entry got row %v", row)
+	}
+}
+
+func TestFirstMultiScoutedEntriesUseTheirSlotRows(t *testing.T) {
+	setupTestEnvironment(t)
+	CachedConfigs.UsingMultiScouting = true
+	InitUserDB() // For the scouters' scores
+	t.Cleanup(func() { userDB.Close() })
+	sink := &recordingSink{}
+	setupTestSink(t, sink)
+	event := GetCurrentEvent()
+
+	slots := []struct {
+		file   string
+		match  uint
+		isBlue bool
+		number int
+		row    int
+	}{
+		{event + "_1_red1_1700000000000.json", 1, false, 1, 2},
+		{event + "_10_blue2_1700000000001.json", 10, true, 2, 2 + 9*6 + 4},
+	}
+
+	for _, slot := range slots {
+		team := &TeamData{TeamNumber: 1816, Scouter: "a"}
+		team.Match.Number = slot.match
+		team.DriverStation.IsBlue = slot.isBlue
+		team.DriverStation.Number = slot.number
+
+		teamBytes, _ := json.Marshal(team)
+		if err := os.WriteFile(filepath.Join(JsonInDirectory, slot.file), teamBytes, 0666); err != nil {
+			t.Fatal(err)
+		}
+		processSubmission(slot.file)
+	}
+
+	expected, _, err := expectedSheetRows(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range slots {
+		row := slot.row
+		if got := sink.row(TabRawData, row); len(got) == 0 {
+			t.Errorf("row %v wasn't written", row)
+		}
+		index := slices.IndexFunc(expected[TabRawData], func(expectedRow SinkRow) bool { return expectedRow.Row == row })
+		if index == -1 || !slices.Equal(cellTexts(expected[TabRawData][index].Values), sink.row(TabRawData, row)) {
+			t.Errorf("row %v isn't where reconciliation expects it", row)
+		}
+	}
+}
+
+func TestUnreadableDBIsntTakenAsEmpty(t *testing.T) {
+	setupTestEnvironment(t)
+	sink := &recordingSink{}
+	setupTestSink(t, sink)
+	sink.WriteRows(TabRawData, []SinkRow{{Row: 2, Values: []interface{}{"written"}}})
+
+	if _, err := matchDB.Exec("drop table entries"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := expectedSheetRows(GetCurrentEvent()); err == nil {
+		t.Error("worked out the sheet without its entries")
+	}
+	if err := RebuildSheetFromDB(""); err == nil {
+		t.Error("rebuilt the sheet without its entries")
+	}
+	if got := sink.row(TabRawData, 2); !slices.Equal(got, []string{"written"}) {
+		t.Errorf("row 2 reads %v after a failed rebuild", got)
+	}
+}
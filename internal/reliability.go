@@ -0,0 +1,203 @@
+package internal
+
+// Tracking how reliable each scouter is, by how often they agree with the other scouters of the same match
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The reliability every scouter starts at, before they have a track record
+const kReliabilityPrior = 0.8
+
+// kReliabilityPrior, as the default value of its column in users.db
+const kReliabilityPriorString = "0.8"
+
+// How many compared fields the prior counts as, so a scouter's first few matches don't swing their reliability wildly
+const kReliabilityPriorWeight = 20
+
+// How often reliability is recomputed if any merge changed
+const kReliabilityRefreshInterval = 30 * time.Second
+
+// Keeps track of when reliability needs recomputing
+var reliabilityRefresh struct {
+	mutex sync.Mutex  // Held while recomputing, so only one recompute runs at a time
+	stale atomic.Bool // If any merge changed since the last recompute
+}
+
+// A scouter's track record
+type ReliabilityInfo struct {
+	Username    string  `json:"Username"`    // The scouter
+	Reliability float64 `json:"Reliability"` // How often they agree with everyone else, from 0 to 1
+	Checks      int     `json:"Checks"`      // How many of their fields were compared against other scouters
+	Misses      int     `json:"Misses"`      // How many of those disagreed with the consensus
+}
+
+// Gets how reliable a scouter is, from 0 to 1. Scouters without a track record get the prior.
+func ScouterReliability(scouter string) float64 {
+	var reliability float64
+	if scanErr := userDB.QueryRow("select reliability from users where username = ?", scouter).Scan(&reliability); scanErr != nil {
+		return kReliabilityPrior
+	}
+	return reliability
+}
+
+// Marks every scouter's reliability as needing a recompute. Cheap enough to call on every merge, since recomputes are batched.
+func MarkReliabilityStale() {
+	reliabilityRefresh.stale.Store(true)
+}
+
+// Recomputes reliability every kReliabilityRefreshInterval, if any merge changed since the last recompute
+func RunReliabilityRefresh() {
+	ticker := time.NewTicker(kReliabilityRefreshInterval)
+	for range ticker.C {
+		refreshReliability()
+	}
+}
+
+// Recomputes reliability if any merge changed since the last recompute
+func refreshReliability() {
+	if reliabilityRefresh.stale.Swap(false) {
+		UpdateScouterReliability()
+	}
+}
+
+// Recomputes every scouter's reliability from the stored merges of every event and saves it in users.db.
+// A scouter misses a field when the other scouters of the match reached a consensus on it that they weren't part of.
+// This reads every merge, so anything that changes merges should call MarkReliabilityStale() instead of this.
+func UpdateScouterReliability() {
+	reliabilityRefresh.mutex.Lock()
+	defer reliabilityRefresh.mutex.Unlock()
+
+	rows, err := matchDB.Query("select report from merges")
+	if err != nil {
+		LogError(err, "Problem executing sql query SELECT report FROM merges")
+		return
+	}
+
+	records := make(map[string]*ReliabilityInfo)
+	record := func(scouter string) *ReliabilityInfo {
+		if _, ok := records[scouter]; !ok {
+			records[scouter] = &ReliabilityInfo{Username: scouter}
+		}
+		return records[scouter]
+	}
+
+	for rows.Next() {
+		var raw string
+		if scanErr := rows.Scan(&raw); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to sql query SELECT report FROM merges")
+			continue
+		}
+
+		var report DisagreementReport
+		if unmarshalErr := json.Unmarshal([]byte(raw), &report); unmarshalErr != nil {
+			LogErrorf(unmarshalErr, "Problem decoding disagreement report %v", raw)
+			continue
+		}
+
+		for _, scouter := range report.Scouters {
+			if scouter != "" {
+				record(scouter).Checks += report.Compared
+			}
+		}
+
+		for _, disagreement := range report.Disagreements {
+			consensus, ok := consensusValue(disagreement.Values)
+			if !ok {
+				continue
+			}
+			for _, value := range disagreement.Values {
+				if value.Scouter != "" && !reflect.DeepEqual(value.Value, consensus) {
+					record(value.Scouter).Misses++
+				}
+			}
+		}
+	}
+	rows.Close()
+
+	tx, err := userDB.Begin()
+	if err != nil {
+		LogError(err, "Problem starting a transaction to update reliability")
+		return
+	}
+
+	// Scouters whose merges are all gone (rolled back, rescouted or rebuilt away) go back to the prior
+	_, execErr := tx.Exec("update users set reliability = ?, reliabilitychecks = 0, reliabilitymisses = 0 where reliabilitychecks > 0", kReliabilityPrior)
+	if execErr != nil {
+		LogError(execErr, "Problem executing sql query UPDATE users SET reliability = ?, reliabilitychecks = 0, reliabilitymisses = 0 WHERE reliabilitychecks > 0")
+		tx.Rollback()
+		return
+	}
+
+	for _, info := range records {
+		info.Reliability = (float64(info.Checks-info.Misses) + kReliabilityPrior*kReliabilityPriorWeight) / float64(info.Checks+kReliabilityPriorWeight)
+
+		_, execErr := tx.Exec("update users set reliability = ?, reliabilitychecks = ?, reliabilitymisses = ? where username = ?",
+			info.Reliability, info.Checks, info.Misses, info.Username)
+		if execErr != nil {
+			LogErrorf(execErr, "Problem executing sql query UPDATE users SET reliability = ?, reliabilitychecks = ?, reliabilitymisses = ? WHERE username = ? with args: %v, %v, %v, %v",
+				info.Reliability, info.Checks, info.Misses, info.Username)
+			tx.Rollback()
+			return
+		}
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		LogError(commitErr, "Problem committing updated reliability")
+	}
+}
+
+// Gets the answer more scouters gave than any other, returning false if there's a tie for the most
+func consensusValue(values []ScouterValue) (any, bool) {
+	var consensus any
+	bestCount, tied := 0, false
+
+	for _, value := range values {
+		count := 0
+		for _, other := range values {
+			if reflect.DeepEqual(value.Value, other.Value) {
+				count++
+			}
+		}
+
+		if count > bestCount {
+			consensus, bestCount, tied = value.Value, count, false
+		} else if count == bestCount && !reflect.DeepEqual(value.Value, consensus) {
+			tied = true
+		}
+	}
+
+	return consensus, !tied
+}
+
+// Gets the track record of every scouter who has one, most reliable first
+func GetScouterReliabilities() []ReliabilityInfo {
+	infos := []ReliabilityInfo{}
+
+	rows, err := userDB.Query("select username, reliability, reliabilitychecks, reliabilitymisses from users where reliabilitychecks > 0")
+	if err != nil {
+		LogError(err, "Problem executing sql query SELECT username, reliability, reliabilitychecks, reliabilitymisses FROM users")
+		return infos
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var info ReliabilityInfo
+		if scanErr := rows.Scan(&info.Username, &info.Reliability, &info.Checks, &info.Misses); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to sql query SELECT username, reliability, reliabilitychecks, reliabilitymisses FROM users")
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Reliability > infos[j].Reliability
+	})
+
+	return infos
+}
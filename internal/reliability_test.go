@@ -0,0 +1,96 @@
+package internal
+
+import "testing"
+
+func TestWeightedMergeFavorsReliableScouters(t *testing.T) {
+	merger := testMerger(nil, 0.9, 0.5, 0.5)
+	merger.weighted = true
+
+	if got, want := merger.Number("auto.scores", []float64{6, 3, 3}, MergeMean), (6*0.9+3*0.5+3*0.5)/1.9; got != want {
+		t.Errorf("weighted mean = %v, want %v", got, want)
+	}
+	if got := merger.Number("auto.scores", []float64{6, 3, 3}, MergeMode); got != 3 {
+		t.Errorf("weighted mode = %v, want 3 as two scouters outweigh one", got)
+	}
+
+	merger = testMerger(nil, 0.9, 0.3, 0.3, 0.2)
+	merger.weighted = true
+	if got := merger.Bool("endgame.parked", []bool{true, false, false, true}, MergeMode); !got {
+		t.Error("weighted mode = false, want true as the reliable scouter tips it")
+	}
+
+	merger.weighted = false
+	if got := merger.Bool("endgame.parked", []bool{true, false, false, true}, MergeMode); !got {
+		t.Error("unweighted mode = false, want the tie to go to the first answer")
+	}
+	if got := merger.Number("auto.scores", []float64{6, 3, 3, 0}, MergeMean); got != 3 {
+		t.Errorf("unweighted mean = %v, want 3", got)
+	}
+}
+
+func TestConsensusValue(t *testing.T) {
+	values := []ScouterValue{{Scouter: "a", Value: 3.0}, {Scouter: "b", Value: 3.0}, {Scouter: "c", Value: 5.0}}
+	if consensus, ok := consensusValue(values); !ok || consensus != 3.0 {
+		t.Errorf("consensus of %v = %v, %v, want 3", values, consensus, ok)
+	}
+
+	tied := []ScouterValue{{Scouter: "a", Value: 3.0}, {Scouter: "b", Value: 5.0}}
+	if _, ok := consensusValue(tied); ok {
+		t.Errorf("found a consensus in %v", tied)
+	}
+}
+
+func TestReliabilityRecomputedWhenMergesChange(t *testing.T) {
+	setupTestEnvironment(t)
+	InitUserDB()
+	t.Cleanup(func() { userDB.Close() })
+	for _, scouter := range []string{"a", "b", "c"} {
+		NewUser(scouter, scouter+"-uuid")
+	}
+
+	refreshReliability() // Anything left stale by other tests
+	StoreMergeResult([]interface{}{}, DisagreementReport{
+		Event: "2026test", Match: 3, DriverStation: "red1", Scouters: []string{"a", "b", "c"}, Compared: 10,
+		Disagreements: []Disagreement{{Field: "auto.scores", Values: []ScouterValue{{"a", 3.0}, {"b", 3.0}, {"c", 5.0}}}},
+	})
+
+	if got := ScouterReliability("c"); got != kReliabilityPrior {
+		t.Errorf("reliability changed to %v before the refresh", got)
+	}
+
+	refreshReliability()
+	want := (float64(10-1) + kReliabilityPrior*kReliabilityPriorWeight) / float64(10+kReliabilityPriorWeight)
+	if got := ScouterReliability("c"); got != want {
+		t.Errorf("reliability of the odd one out = %v, want %v", got, want)
+	}
+	if got := ScouterReliability("a"); got <= want {
+		t.Errorf("reliability of a scouter who agreed = %v, want more than %v", got, want)
+	}
+}
+
+func TestReliabilityResetWhenMergesAreGone(t *testing.T) {
+	setupTestEnvironment(t)
+	InitUserDB()
+	t.Cleanup(func() { userDB.Close() })
+	for _, scouter := range []string{"a", "b", "c"} {
+		NewUser(scouter, scouter+"-uuid")
+	}
+
+	StoreMergeResult([]interface{}{}, DisagreementReport{
+		Event: "2026test", Match: 4, DriverStation: "blue2", Scouters: []string{"a", "b", "c"}, Compared: 10,
+		Disagreements: []Disagreement{{Field: "auto.scores", Values: []ScouterValue{{"a", 3.0}, {"b", 3.0}, {"c", 5.0}}}},
+	})
+	refreshReliability()
+	if got := ScouterReliability("c"); got == kReliabilityPrior {
+		t.Fatal("reliability wasn't computed")
+	}
+
+	ClearMergeResult("2026test", MatchInfo{Number: 4}, "blue2")
+	refreshReliability()
+	if got := ScouterReliability("c"); got != kReliabilityPrior {
+		t.Errorf("reliability of a scouter without merges = %v, want the prior", got)
+	}
+	if infos := GetScouterReliabilities(); len(infos) != 0 {
+		t.Errorf("scouters without merges still have a track record: %+v", infos)
+	}
+}
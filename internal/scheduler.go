@@ -85,6 +85,7 @@ func AddIndividualSchedule(name string, nameIsUUID bool, ranges ScoutRanges) {
 	}
 
 	rangeString := string(rangeBytes)
+	defer MarkCoverageStale()
 
 	if userInSchedule(scoutDB, uuid) { //If doesn't exist
 		cachedRanges := retrieveScouterAsObject(name, nameIsUUID)
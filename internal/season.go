@@ -0,0 +1,130 @@
+package internal
+
+// The registry of per-season game definitions.
+// Everything about scouting data that changes every January (payloads, merging, sheet columns, validation, storage)
+// lives in one game_YEAR.go file that registers itself here. Which one is used is chosen by the year of the event key.
+
+import (
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strconv"
+)
+
+// The parts of a match entry that every season has, no matter how its payload is laid out
+type EntryInfo struct {
+	TeamNumber    uint64            // The team number
+	Match         MatchInfo         // The match number and if it is a replay
+	Scouter       string            // The person who scouted it
+	DriverStation DriverStationData // The driverstation it was scouted from
+	Rescouting    bool              // If it replaces everything else for its match and driverstation
+	Prescouting   bool              // If it was scouted before the event
+	SubmissionID  string            // The client-generated ID used to deduplicate retries, if any
+}
+
+// A match scouting entry of any season. Implemented by pointers to each season's payload type.
+type MatchEntry interface {
+	Info() EntryInfo           // The parts of the entry every season shares
+	SetScouter(scouter string) // Overwrites who the entry says scouted it
+}
+
+// A pit scouting entry of any season. Implemented by pointers to each season's payload type.
+type PitEntry interface {
+	Team() int         // The team that was pit scouted
+	ScoutedBy() string // The person who did the pit scouting
+}
+
+// Builds one cell of a RawData or Prescouting row out of every entry behind it (just one, unless it is a merged row)
+// and what the season's CompileRow made of them, which is nil if the season doesn't have one
+type MatchFormatter func(entries []MatchEntry, compiled any) interface{}
+
+// Builds one cell of a PitScouting row
+type PitFormatter func(pit PitEntry) interface{}
+
+// One season's game
+type GameSeason struct {
+	Year int    // The year, as found at the start of event keys
+	Name string // The name of the game
+
+	NewMatchEntry func() MatchEntry // Allocates an empty match payload to decode into
+	NewPitEntry   func() PitEntry   // Allocates an empty pit payload to decode into
+
+	ValidateMatch func(entry MatchEntry) []FieldError // Checks the game-specific fields of a match entry. Optional.
+
+	MatchColumns  []string                                           // The headers of the RawData tab
+	MatchRow      func(entry MatchEntry) []interface{}               // Builds the RawData row of a single entry
+	MergedRow     func(entries []MatchEntry) []interface{}           // Builds the RawData row of several entries of the same match and driverstation
+	PrescoutRow   func(entry MatchEntry) []interface{}               // Builds the Prescouting row of a prescouted entry
+	PrescoutCols  []string                                           // The headers of the Prescouting tab
+	PitColumns    []string                                           // The headers of the PitScouting tab
+	PitRow        func(pit PitEntry) []interface{}                   // Builds the PitScouting row of a pit entry
+	Tables        []string                                           // Create statements for any tables the season keeps in matches.db
+	StoreMatch    func(tx *sql.Tx, id int64, entry MatchEntry) error // Stores a match entry into the season's tables. Optional.
+	StorePitEntry func(tx *sql.Tx, id int64, pit PitEntry) error     // Stores a pit entry into the season's tables. Optional.
+
+	MatchFormatters map[string]MatchFormatter      // Named cells that sheet layouts in the config can use on the RawData and Prescouting tabs
+	CompileRow      func(entries []MatchEntry) any // Combines the entries behind a row once for all of its MatchFormatters, so each cell doesn't merge them again. Optional.
+	PitFormatters   map[string]PitFormatter        // Named cells that sheet layouts in the config can use on the PitScouting tab
+
+	UncomparedFields []string // Json paths of fields scouters aren't expected to agree on (who scouted it, notes), left out of disagreement reports
+}
+
+// Every registered season, by year
+var seasons = make(map[int]*GameSeason)
+
+// Adds a season to the registry. Called from the init() of each game_YEAR.go.
+func RegisterSeason(season *GameSeason) {
+	if _, exists := seasons[season.Year]; exists {
+		panic("Season " + strconv.Itoa(season.Year) + " registered twice")
+	}
+	seasons[season.Year] = season
+}
+
+// Gets the season registered for a year, returning false if there isn't one
+func GetSeason(year int) (*GameSeason, bool) {
+	season, ok := seasons[year]
+	return season, ok
+}
+
+// Gets the newest registered season
+func LatestSeason() *GameSeason {
+	var years []int
+	for year := range seasons {
+		years = append(years, year)
+	}
+
+	return seasons[slices.Max(years)]
+}
+
+// Gets the season of an event key (2026njfla -> 2026).
+// Custom event keys and years nobody wrote a game for fall back to the newest season.
+func SeasonForEvent(eventKey string) *GameSeason {
+	if len(eventKey) >= 4 {
+		if year, err := strconv.Atoi(eventKey[:4]); err == nil {
+			if season, ok := GetSeason(year); ok {
+				return season
+			}
+		}
+	}
+
+	return LatestSeason()
+}
+
+// Gets the season of the event currently configured
+func CurrentSeason() *GameSeason {
+	return SeasonForEvent(GetCurrentEvent())
+}
+
+// Decodes a match entry in the payload format of the passed in season
+func (season *GameSeason) DecodeMatch(data []byte) (MatchEntry, error) {
+	entry := season.NewMatchEntry()
+	err := json.Unmarshal(data, entry)
+	return entry, err
+}
+
+// Decodes a pit entry in the payload format of the passed in season
+func (season *GameSeason) DecodePit(data []byte) (PitEntry, error) {
+	pit := season.NewPitEntry()
+	err := json.Unmarshal(data, pit)
+	return pit, err
+}
@@ -13,7 +13,6 @@ import (
 	"path/filepath"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -23,110 +22,189 @@ func SetSecureCookies(value bool) {
 	secureCookies = value
 }
 
-// Runs the infinite server loop with a looptime of 5 seconds.
+// Starts the ingestion workers, then keeps scanning the In directory for anything that wasn't queued directly.
 func RunServerLoop() {
-	ticker := time.NewTicker(5 * time.Second)
-	quit := make(chan struct{})
-	func() {
-		for {
-			select {
-			case <-ticker.C:
-				go iterativeServerCall()
-			case <-quit:
-				ticker.Stop()
-				return
-			}
-		}
-	}()
+	startIngestWorkers()
+	scanInDirectory()
+
+	ticker := time.NewTicker(kIngestScanInterval)
+	for range ticker.C {
+		scanInDirectory()
+	}
 }
 
-// The call to read and parse one file in InputtedJson
-func iterativeServerCall() {
-	allJson, readErr := os.ReadDir(JsonInDirectory)
-	if readErr != nil {
-		LogErrorf(readErr, "Problem reading file %v", JsonInDirectory)
+// Parses one file in the In directory and writes it to the spreadsheet.
+// Failures are handed to handleIngestFailure() to be retried or dead-lettered.
+func processSubmission(fileName string) {
+	inPath := filepath.Join(JsonInDirectory, fileName)
+	season := SeasonForEvent(eventFromFileName(fileName))
+
+	if isPitFile(fileName) { // Pit Scouting // TODO: Change how we seperate the JSON yeah? Also change JSON file name format too. -Leon
+		pit, parseErr := readPitEntry(inPath)
+		if parseErr != nil { // Handle any errors opening
+			handleIngestFailure(fileName, parseErr)
+			return
+		}
+
+		if storeErr := StorePitEntry(fileName, pit, EntryQueued); storeErr != nil {
+			handleIngestFailure(fileName, storeErr)
+			return
+		}
+
+		row, rowErr := GetTeamRow(eventFromFileName(fileName), TabPitScouting, pit.Team())
+		if rowErr != nil {
+			handleIngestFailure(fileName, rowErr)
+			return
+		}
+
+		// Every visit to the team is merged onto their row
+		merged, mergeErr := mergedTeamPit(season, eventFromFileName(fileName), pit.Team(), pendingPitVisit(fileName, pit))
+		if mergeErr != nil {
+			handleIngestFailure(fileName, mergeErr)
+			return
+		}
+
+		if writeErr := WritePitDataToLine(season, merged, row); writeErr != nil { // Handle any errors writing
+			handleIngestFailure(fileName, writeErr)
+			return
+		}
+
+		MoveFile(inPath, filepath.Join(JsonPitWrittenDirectory, fileName))
+		SetEntryState(fileName, EntryWritten)
+		clearRetryState(fileName)
+		recordProcessed(fileName)
+		LogMessagef("Successfully Processed %v ", fileName)
+		ModifyUserScore(pit.ScoutedBy(), Increase, 1)
 		return
 	}
 
-	// Avoid nil files
-	if len(allJson) > 0 {
-		// Only deal with first file
-		file := allJson[0]
+	team, parseErr := readMatchEntry(inPath)
+	if parseErr != nil {
+		handleIngestFailure(fileName, parseErr)
+		return
+	}
+	info := team.Info()
 
-		// Parse and write to spreadsheet
-		if len(strings.Split(file.Name(), "_")) == 2 { // Pit Scouting // TODO: Change how we seperate the JSON yeah? Also change JSON file name format too. -Leon
-			pit, hadErrs := ParsePitScout(file.Name())
+	if info.Prescouting { // Prescouting is kept apart from the event's matches, and every team has one row that is written over
+		event := eventFromFileName(fileName)
+		if storeErr := StorePrescoutEntry(fileName, team, EntryQueued); storeErr != nil {
+			handleIngestFailure(fileName, storeErr)
+			return
+		}
 
-			if !hadErrs {
-				if WritePitDataToLine(pit, GetPitRow(pit.TeamNumber)) {
-					MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonPitWrittenDirectory, file.Name()))
-					LogMessagef("Successfully Processed %v ", file.Name())
-					ModifyUserScore(pit.Scouter, Increase, 1)
-				} else { // Handle any errors writing
-					MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
-					LogMessagef("Errors in writing %v to sheet, moved to %v", filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
-				}
-			} else { // Handle any errors opening
-				MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
-				LogMessagef("Errors in processing %v, moved to %v", filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
+		row, rowErr := GetTeamRow(event, TabPrescouting, int(info.TeamNumber))
+		if rowErr != nil {
+			handleIngestFailure(fileName, rowErr)
+			return
+		}
+
+		// An older entry that was retried shouldn't write over a newer one
+		written, writtenErr := GetStoredPrescoutEntries(event, EntryWritten)
+		if writtenErr != nil {
+			handleIngestFailure(fileName, writtenErr)
+			return
+		}
+		newest := team
+		for _, stored := range written {
+			if stored.Data.Info().TeamNumber == info.TeamNumber && stored.Received.After(receivedFromFileName(fileName)) {
+				newest = stored.Data
 			}
-		} else {
-			team, hadErrs := Parse(file.Name(), false)
-
-			var successfullyWrote bool
-
-			if !hadErrs {
-				if allMatching := GetAllMatching(file.Name()); CachedConfigs.UsingMultiScouting && len(allMatching) > 0 { // Multi-scouting
-					var entries []TeamData
-					entries = append(entries, team)
-					for _, foundFile := range allMatching {
-						if team.Rescouting { // If rescouting, discard other ones
-							if !MoveFile(filepath.Join(JsonWrittenDirectory, foundFile), filepath.Join(JsonDiscardedDirectory, foundFile)) {
-								LogMessage("File " + filepath.Join(JsonWrittenDirectory, foundFile) + " unable to be moved to Discarded")
-							}
-						} else {
-							// Parse and add to parsed data
-							parsedData, foundErrs := Parse(foundFile, true)
-							if !foundErrs {
-								entries = append(entries, parsedData)
-							} else {
-								if !MoveFile(filepath.Join(JsonWrittenDirectory, foundFile), filepath.Join(JsonErroredDirectory, foundFile)) {
-									FatalLogMessage("File " + filepath.Join(JsonWrittenDirectory, foundFile) + " unable to be moved to Errored, investigate this!")
-								}
-							}
-						}
-					}
-
-					if team.Rescouting {
-						successfullyWrote = WriteTeamDataToLine(team, GetRow(team))
-					} else {
-						successfullyWrote = WriteMultiScoutedTeamDataToLine(
-							CompileMultiMatch(entries...),
-							GetRow(team),
-							entries,
-						)
-					}
-				} else { // Single scouting
-					successfullyWrote = WriteTeamDataToLine(team, 2) //sheetWriter.go's append will make sure this won't override another bit of data
-					//successfullyWrote = WriteTeamDataToLine(team, GetRow(team))
-				}
+		}
 
-				//Currently, there is no handling if one can't move. It will loop infinitley. This could be something to improve.
-				if successfullyWrote {
-					MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonWrittenDirectory, file.Name()))
-					LogMessagef("Successfully Processed %v ", file.Name())
-					ModifyUserScore(team.Scouter, Increase, 1)
+		if writeErr := WritePrescoutDataToLine(season, newest, row); writeErr != nil {
+			handleIngestFailure(fileName, writeErr)
+			return
+		}
+
+		MoveFile(inPath, filepath.Join(JsonPrescoutedDirectory, fileName))
+		SetEntryState(fileName, EntryWritten)
+		clearRetryState(fileName)
+		recordProcessed(fileName)
+		LogMessagef("Successfully Processed %v ", fileName)
+		ModifyUserScore(info.Scouter, Increase, 1)
+		return
+	}
+
+	if storeErr := StoreMatchEntry(fileName, team, EntryQueued); storeErr != nil {
+		handleIngestFailure(fileName, storeErr)
+		return
+	}
+
+	var writeErr error
+	var superseded []string
+
+	replay := MatchInfo{Number: info.Match.Number, IsReplay: true}
+	replayed := !info.Match.IsReplay && len(GetSlotFiles(eventFromFileName(fileName), replay, info.DriverStation.IsBlue, info.DriverStation.Number, EntryWritten)) > 0
+
+	if allMatching := GetAllMatching(fileName, info); CachedConfigs.UsingMultiScouting && replayed { // The replay's row takes precedence over the original match
+		LogMessagef("Match %v was replayed, so %v is stored without being written to the sheet", info.Match.Number, fileName)
+	} else if CachedConfigs.UsingMultiScouting && len(allMatching) > 0 { // Multi-scouting
+		var entries []MatchEntry
+		entries = append(entries, team)
+		for _, foundFile := range allMatching {
+			if info.Rescouting { // If rescouting, the other ones are superseded once it is written
+				superseded = append(superseded, foundFile)
+			} else {
+				// Parse and add to parsed data
+				parsedData, foundErr := readMatchEntry(filepath.Join(JsonWrittenDirectory, foundFile))
+				if foundErr == nil {
+					entries = append(entries, parsedData)
 				} else {
-					MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
-					LogMessagef("Errors in writing %v to sheet, moved to %v", filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
+					now := time.Now()
+					deadLetter(JsonWrittenDirectory, DeadLetterRecord{
+						File:         foundFile,
+						Error:        foundErr.Error(),
+						Attempts:     1,
+						FirstAttempt: now,
+						LastAttempt:  now,
+						DeadLettered: now,
+					})
 				}
-			} else {
-				MoveFile(filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
-				LogMessagef("Errors in processing %v, moved to %v", filepath.Join(JsonInDirectory, file.Name()), filepath.Join(JsonErroredDirectory, file.Name()))
 			}
+		}
+
+		if info.Rescouting {
+			writeErr = WriteTeamDataToRow(season, team, GetRow(info))
+		} else {
+			writeErr = WriteMultiScoutedTeamDataToLine(
+				season,
+				eventFromFileName(fileName),
+				entries,
+				GetRow(info),
+			)
+		}
+	} else if CachedConfigs.UsingMultiScouting { // The first entry of its match slot, on the slot's row
+		writeErr = WriteTeamDataToRow(season, team, GetRow(info))
+	} else { // Single scouting, where every entry keeps the row it is first written to
+		row, rowErr := GetEntryRow(eventFromFileName(fileName), fileName)
+		if rowErr != nil {
+			writeErr = rowErr
+		} else {
+			writeErr = WriteTeamDataToRow(season, team, row)
+		}
+	}
+
+	if writeErr != nil {
+		handleIngestFailure(fileName, writeErr)
+		return
+	}
 
+	if len(superseded) > 0 {
+		if versionErr := supersedeSlot(eventFromFileName(fileName), info, fileName, superseded); versionErr != nil {
+			handleIngestFailure(fileName, versionErr)
+			return
 		}
+		ClearMergeResult(eventFromFileName(fileName), info.Match, GetDSString(info.DriverStation.IsBlue, uint(info.DriverStation.Number)))
+	} else if CachedConfigs.UsingMultiScouting && !replayed {
+		addToCurrentVersion(eventFromFileName(fileName), info, fileName)
 	}
+
+	MoveFile(inPath, filepath.Join(JsonWrittenDirectory, fileName))
+	SetEntryState(fileName, EntryWritten)
+	clearRetryState(fileName)
+	recordProcessed(fileName)
+	LogMessagef("Successfully Processed %v ", fileName)
+	ModifyUserScore(info.Scouter, Increase, 1)
 }
 
 // Returns a configured server object
@@ -150,10 +228,12 @@ func SetupServer() *http.Server {
 	http.HandleFunc("/logout", handleWithCORS(handleLogoutRequest, false))
 
 	//Any Authentication
-	http.HandleFunc("/dataEntry", handleWithCORS(postTeamData, true))
+	http.HandleFunc("/dataEntry", handleWithCORS(postTeamData, false))
 	http.HandleFunc("/pitScout", handleWithCORS(postPitScout, true))
+	http.HandleFunc("/dataEntryBatch", handleWithCORS(postSubmissionBatch, true))
 	http.HandleFunc("/singleSchedule", handleWithCORS(serveScouterSchedule, true))
 	http.HandleFunc("/getTheme", handleWithCORS(serveTheme, false))
+	http.HandleFunc("/submissionStatus", handleWithCORS(serveSubmissionStatus, false))
 
 	//Admin or curr user
 	http.HandleFunc("/setDisplayName", handleWithCORS(setDisplayName, true))
@@ -175,6 +255,23 @@ func SetupServer() *http.Server {
 	http.HandleFunc("/keyChange", handleWithCORS(handleKeyChange, false))
 	http.HandleFunc("/sheetChange", handleWithCORS(handleSheetChange, false))
 	http.HandleFunc("/adminUserInfo", handleWithCORS(serveUserInfoForAdmins, true))
+	http.HandleFunc("/rebuildSheet", handleWithCORS(handleSheetRebuild, true))
+	http.HandleFunc("/provisionSheet", handleWithCORS(handleSheetProvision, false))
+	http.HandleFunc("/reconcileSheet", handleWithCORS(handleSheetReconcile, false))
+	http.HandleFunc("/mangled", handleWithCORS(serveMangledList, false))
+	http.HandleFunc("/mangledEntry", handleWithCORS(serveMangledEntry, false))
+	http.HandleFunc("/repairMangled", handleWithCORS(handleMangledRepair, false))
+	http.HandleFunc("/pipelineEntries", handleWithCORS(servePipelineEntries, false))
+	http.HandleFunc("/pipelineEntry", handleWithCORS(servePipelineEntry, false))
+	http.HandleFunc("/pipelineMove", handleWithCORS(handlePipelineMove, false))
+	http.HandleFunc("/pipelineAudit", handleWithCORS(servePipelineAudit, false))
+	http.HandleFunc("/ingestStatus", handleWithCORS(serveIngestStatus, false))
+	http.HandleFunc("/disagreements", handleWithCORS(serveDisagreements, false))
+	http.HandleFunc("/scouterReliability", handleWithCORS(serveScouterReliability, false))
+	http.HandleFunc("/entryHistory", handleWithCORS(serveEntryHistory, false))
+	http.HandleFunc("/rollbackEntry", handleWithCORS(handleEntryRollback, false))
+	http.HandleFunc("/pitHistory", handleWithCORS(servePitHistory, false))
+	http.HandleFunc("/outputSinks", handleWithCORS(serveOutputSinks, false))
 
 	jsrv := &http.Server{ //TODO: love of god add an https thing -Leon
 		// Addr: ":8443",
@@ -208,47 +305,20 @@ func postTeamData(writer http.ResponseWriter, request *http.Request) {
 		LogErrorf(readErr, "Problem reading %v", request.Body)
 	}
 
-	var team TeamData
-	unmarshalErr := json.Unmarshal(requestBytes, &team)
-	team.Scouter = auth.Username // We shouldnt trust the client to send us the correct username
+	team, unmarshalErr := CurrentSeason().DecodeMatch(requestBytes)
+	team.SetScouter(auth.Username) // We shouldnt trust the client to send us the correct username
 
 	if unmarshalErr != nil { // Handle mangling
-		LogErrorf(unmarshalErr, "MANGLED: %v", requestBytes)
-
-		newFileName := filepath.Join(JsonMangledDirectory, time.Now().String()+".json")
-		mangledFile, openErr := OpenWithPermissions(newFileName)
-		if openErr != nil {
-			LogErrorf(openErr, "Problem creating %v", newFileName)
-		}
-
-		defer mangledFile.Close()
+		LogErrorf(unmarshalErr, "MANGLED: %v", string(requestBytes))
+		StoreMangled(MangledMatch, auth.Username, requestBytes, unmarshalErr)
 
 		writer.WriteHeader(500)
 
 		httpResponsef(writer, "Problem writing http response to Mangled JSON", ":(")
 	} else { // Handle successful unmarshalling
-		//EVENT_MATCH_{COLOR}{DSNUM}_SystemTimeMS
-		//TODO: file naming stuff here -Leon
-		fileName := fmt.Sprintf(
-			"%s_%v_%s_%v",
-			GetCurrentEvent(),
-			team.Match.Number,
-			GetDSString(team.DriverStation.IsBlue, uint(team.DriverStation.Number)),
-			time.Now().UnixMilli(),
-		)
-
-		file, openErr := OpenWithPermissions(filepath.Join(JsonInDirectory, fileName+".json"))
-		if openErr != nil {
-			LogErrorf(openErr, "Problem creating %v", filepath.Join(JsonInDirectory, fileName+".json"))
-		}
-		defer file.Close()
-
-		encodeErr := json.NewEncoder(file).Encode(&team)
-		if encodeErr != nil {
-			LogErrorf(encodeErr, "Problem encoding %v", team)
-		}
+		result := SubmitMatchEntry(team)
 
-		httpResponsef(writer, "Problem writing http response to JSON post request", "Processed %v\n", fileName)
+		writeSubmissionResponse(writer, result)
 	}
 }
 
@@ -263,47 +333,130 @@ func postPitScout(writer http.ResponseWriter, request *http.Request) {
 			LogErrorf(readErr, "Problem reading %v", request.Body)
 		}
 
-		var pit PitScoutingData
-		unmarshalErr := json.Unmarshal(requestBytes, &pit)
+		pit, unmarshalErr := CurrentSeason().DecodePit(requestBytes)
 
 		if unmarshalErr != nil { // Handling mangling
-			LogErrorf(unmarshalErr, "MANGLED: %v", requestBytes)
+			LogErrorf(unmarshalErr, "MANGLED: %v", string(requestBytes))
+			StoreMangled(MangledPit, auth.Username, requestBytes, unmarshalErr)
 
-			newFileName := filepath.Join(JsonMangledDirectory, time.Now().String()+".json")
-			mangledFile, openErr := OpenWithPermissions(newFileName)
-			if openErr != nil {
-				LogErrorf(openErr, "Problem creating %v", newFileName)
-			}
+			writer.WriteHeader(500)
 
-			defer mangledFile.Close()
+			httpResponsef(writer, "Problem writing http response to Mangled JSON", ":(")
+		} else {
+			writeSubmissionResponse(writer, SubmitPitEntry(pit))
+		}
+	} else {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to JSON post request with insufficient authentication", "Not authenticated :(")
+	}
+}
 
+// Writes the plain text response to a single submission. Rejections caused by the entry itself are a 400, problems on the server a 500.
+func writeSubmissionResponse(writer http.ResponseWriter, result SubmissionResult) {
+	if len(result.Errors) > 0 { // Let the frontend highlight what's wrong
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(422)
+		encodeErr := json.NewEncoder(writer).Encode(result.Errors)
+		if encodeErr != nil {
+			LogErrorf(encodeErr, "Problem encoding %v", result.Errors)
+		}
+		return
+	}
+
+	switch result.Status {
+	case SubmissionAccepted:
+		httpResponsef(writer, "Problem writing http response to JSON post request", "Processed %v\n", result.File)
+	case SubmissionDuplicate:
+		httpResponsef(writer, "Problem writing http response to duplicate JSON post request", "Already received as %v\n", result.File)
+	default:
+		if result.invalid { // The client sent something that won't ever be accepted, so it shouldn't retry it
+			writer.WriteHeader(400)
+		} else {
 			writer.WriteHeader(500)
+		}
+		httpResponsef(writer, "Problem writing http response to rejected JSON post request", "%v :(\n", result.Reason)
+	}
+}
 
-			httpResponsef(writer, "Problem writing http response to Mangled JSON", ":(")
+// A batch of entries uploaded at once, usually by a tablet that has been offline
+type submissionBatch struct {
+	Matches []json.RawMessage `json:"matches"`
+	Pit     []json.RawMessage `json:"pit"`
+}
+
+// The result of one entry in a batch, reported in the same order the entries were sent
+type batchItemResult struct {
+	Kind  string `json:"Kind"`  // "match" or "pit"
+	Index int    `json:"Index"` // The position of the entry in its list
+	SubmissionResult
+}
+
+// Handles posting many match and pit scouting entries at once.
+// Every entry goes down the same path as /dataEntry and /pitScout, and is answered on its own.
+func postSubmissionBatch(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request) // Don't care about specific role for post, everyone that is auth'd can.
+	if !auth.Authed {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to batch post request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	requestBytes, readErr := io.ReadAll(request.Body)
+	if readErr != nil {
+		LogErrorf(readErr, "Problem reading %v", request.Body)
+	}
+
+	var batch submissionBatch
+	if unmarshalErr := json.Unmarshal(requestBytes, &batch); unmarshalErr != nil {
+		// Also accept a plain array of match entries
+		if arrayErr := json.Unmarshal(requestBytes, &batch.Matches); arrayErr != nil {
+			LogErrorf(unmarshalErr, "Problem unmarshalling batch %v", string(requestBytes))
+			writer.WriteHeader(400)
+			httpResponsef(writer, "Problem writing http response to mangled batch", "Batch is not valid JSON :(")
+			return
+		}
+	}
+
+	season := CurrentSeason()
+	results := []batchItemResult{}
+
+	for i, raw := range batch.Matches {
+		result := batchItemResult{Kind: "match", Index: i}
+
+		team, unmarshalErr := season.DecodeMatch(raw)
+		if unmarshalErr != nil {
+			LogErrorf(unmarshalErr, "MANGLED: %v", string(raw))
+			result.Status = SubmissionRejected
+			result.Reason = unmarshalErr.Error()
+			result.File = StoreMangled(MangledMatch, auth.Username, raw, unmarshalErr)
 		} else {
-			//EVENT_TEAM.json
-			fileName := fmt.Sprintf(
-				"%s_%v",
-				GetCurrentEvent(),
-				pit.TeamNumber,
-			)
+			team.SetScouter(auth.Username) // We shouldnt trust the client to send us the correct username
+			result.SubmissionResult = SubmitMatchEntry(team)
+		}
 
-			file, openErr := OpenWithPermissions(filepath.Join(JsonInDirectory, fileName+".json"))
-			if openErr != nil {
-				LogErrorf(openErr, "Problem creating %v", filepath.Join(JsonInDirectory, fileName+".json"))
-			}
-			defer file.Close()
+		results = append(results, result)
+	}
 
-			encodeErr := json.NewEncoder(file).Encode(&pit)
-			if encodeErr != nil {
-				LogErrorf(encodeErr, "Problem encoding %v", pit)
-			}
+	for i, raw := range batch.Pit {
+		result := batchItemResult{Kind: "pit", Index: i}
 
-			httpResponsef(writer, "Problem writing http response to JSON post request", "Processed %v\n", fileName)
+		pit, unmarshalErr := season.DecodePit(raw)
+		if unmarshalErr != nil {
+			LogErrorf(unmarshalErr, "MANGLED: %v", string(raw))
+			result.Status = SubmissionRejected
+			result.Reason = unmarshalErr.Error()
+			result.File = StoreMangled(MangledPit, auth.Username, raw, unmarshalErr)
+		} else {
+			result.SubmissionResult = SubmitPitEntry(pit)
 		}
-	} else {
-		writer.WriteHeader(500)
-		httpResponsef(writer, "Problem writing http response to JSON post request with insufficient authentication", "Not authenticated :(")
+
+		results = append(results, result)
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(results)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", results)
 	}
 }
 
@@ -449,6 +602,516 @@ func handleSheetChange(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+// Handles requests to rewrite the spreadsheet from matches.db. The sink query parameter rebuilds only that output sink.
+func handleSheetRebuild(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to sheet rebuild request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	if rebuildErr := RebuildSheetFromDB(request.URL.Query().Get("sink")); rebuildErr != nil {
+		httpResponsef(writer, "Problem writing http response to unsuccessful sheet rebuild", "There was a problem rebuilding the sheet: %v\n", rebuildErr)
+		return
+	}
+
+	httpResponsef(writer, "Problem writing http response to successful sheet rebuild", "Successfully rebuilt the sheet from matches.db\n")
+}
+
+// Handles requests to set up the spreadsheet of the current event, whether or not anything changed
+func handleSheetProvision(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to sheet provision request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	if provisionErr := ProvisionSpreadsheet(); provisionErr != nil {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to unsuccessful sheet provision", "There was a problem provisioning the spreadsheet: %v\n", provisionErr)
+		return
+	}
+
+	httpResponsef(writer, "Problem writing http response to successful sheet provision", "Successfully provisioned spreadsheet %v for %v\n", SpreadsheetId, GetCurrentEvent())
+}
+
+// Handles checking the sheet against matches.db. With the rewrite query parameter set to true, rows that don't match are rewritten.
+func handleSheetReconcile(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to sheet reconcile request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	report, reconcileErr := ReconcileSheet(request.URL.Query().Get("rewrite") == "true")
+	if reconcileErr != nil {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to unsuccessful sheet reconcile", "There was a problem reconciling the sheet: %v\n", reconcileErr)
+		return
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(report)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", report)
+	}
+}
+
+// Handles listing every mangled submission
+func serveMangledList(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to mangled list request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	records := GetMangledRecords()
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(records)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", records)
+	}
+}
+
+// Handles viewing one mangled submission, named by the Filename header
+func serveMangledEntry(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to mangled entry request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	record, ok := GetMangledRecord(request.Header.Get("Filename"))
+	if !ok {
+		writer.WriteHeader(404)
+		httpResponsef(writer, "Problem writing http response to missing mangled entry", "No mangled submission %v\n", request.Header.Get("Filename"))
+		return
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(record)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", record)
+	}
+}
+
+// Handles repairing a mangled submission, named by the Filename header, and queueing it in In.
+// The body is the corrected payload. An empty body tries the original payload again.
+func handleMangledRepair(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to mangled repair request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	requestBytes, readErr := io.ReadAll(request.Body)
+	if readErr != nil {
+		LogErrorf(readErr, "Problem reading %v", request.Body)
+	}
+
+	result, repairErr := RepairMangled(request.Header.Get("Filename"), requestBytes, auth.Username)
+	if repairErr != nil {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to unsuccessful mangled repair", "Unable to repair %v: %v\n", request.Header.Get("Filename"), repairErr)
+		return
+	}
+
+	writeSubmissionResponse(writer, result)
+}
+
+// Handles listing the files in the json pipeline. Filtered by the state, event, match, team and scouter query parameters.
+func servePipelineEntries(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to pipeline listing request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	query := request.URL.Query()
+	filter := PipelineFilter{
+		Directory: query.Get("state"),
+		Event:     query.Get("event"),
+		Scouter:   query.Get("scouter"),
+	}
+
+	if _, ok := pipelineDirectory(filter.Directory); filter.Directory != "" && !ok {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to pipeline listing with an unknown state", "Unknown state %v\n", filter.Directory)
+		return
+	}
+
+	if match := query.Get("match"); match != "" {
+		parsed, parseErr := strconv.ParseUint(match, 10, 64)
+		if parseErr != nil {
+			writer.WriteHeader(400)
+			httpResponsef(writer, "Problem writing http response to pipeline listing with a bad match", "Bad match number %v\n", match)
+			return
+		}
+		filter.Match = uint(parsed)
+	}
+
+	if team := query.Get("team"); team != "" {
+		parsed, parseErr := strconv.Atoi(team)
+		if parseErr != nil {
+			writer.WriteHeader(400)
+			httpResponsef(writer, "Problem writing http response to pipeline listing with a bad team", "Bad team number %v\n", team)
+			return
+		}
+		filter.Team = parsed
+	}
+
+	entries := ListPipelineEntries(filter)
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(entries)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", entries)
+	}
+}
+
+// Handles serving one file in the json pipeline, named by the state and file query parameters
+func servePipelineEntry(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to pipeline entry request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	state, file := request.URL.Query().Get("state"), request.URL.Query().Get("file")
+
+	details, ok := GetPipelineEntry(state, file)
+	if !ok {
+		writer.WriteHeader(404)
+		httpResponsef(writer, "Problem writing http response to missing pipeline entry", "No file %v in %v\n", file, state)
+		return
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(details)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", details)
+	}
+}
+
+// A request to move a file through the json pipeline
+type pipelineMoveRequest struct {
+	File   string `json:"File"`   // The name of the file
+	State  string `json:"State"`  // The state it is in now
+	Action string `json:"Action"` // requeue, discard or restore
+	Note   string `json:"Note"`   // Why, for the audit log. Optional.
+}
+
+// Handles requeueing, discarding or restoring a file in the json pipeline
+func handlePipelineMove(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to pipeline move request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	var move pipelineMoveRequest
+	if decodeErr := json.NewDecoder(request.Body).Decode(&move); decodeErr != nil {
+		LogErrorf(decodeErr, "Problem decoding %v", request.Body)
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to malformed pipeline move", "Malformed request :(\n")
+		return
+	}
+
+	to, moveErr := MovePipelineEntry(move.Action, move.State, move.File, auth.Username, move.Note)
+	if moveErr != nil {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to unsuccessful pipeline move", "Unable to %v %v: %v\n", move.Action, move.File, moveErr)
+		return
+	}
+
+	if move.State == PipelineWritten || move.State == PipelinePitWritten || move.State == PipelinePrescouted {
+		httpResponsef(writer, "Problem writing http response to pipeline move", "Moved %v to %v. Rebuild the sheet to take it off.\n", move.File, to)
+		return
+	}
+
+	httpResponsef(writer, "Problem writing http response to pipeline move", "Moved %v to %v\n", move.File, to)
+}
+
+// Handles serving every recorded action taken on the json pipeline. The file query parameter narrows it to one file.
+func servePipelineAudit(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to pipeline audit request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	actions := GetPipelineActions(request.URL.Query().Get("file"))
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(actions)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", actions)
+	}
+}
+
+// Handles serving where the logged in user's submissions are in the pipeline.
+// Each file query parameter asks about one file by the name it was returned as. With none, every submission at the current event is served.
+func serveSubmissionStatus(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+
+	if auth.Preflight {
+		writer.WriteHeader(200)
+		return
+	}
+
+	if !auth.Authed {
+		writer.WriteHeader(401)
+		httpResponsef(writer, "Problem writing http response to submission status request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	var receipts []SubmissionReceipt
+	if files := request.URL.Query()["file"]; len(files) > 0 {
+		for _, file := range files {
+			receipts = append(receipts, GetSubmissionReceipt(file, auth.Username, auth.IsAdmin()))
+		}
+	} else {
+		receipts = GetScouterReceipts(auth.Username)
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(receipts)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", receipts)
+	}
+}
+
+// Handles serving a summary of how ingestion is doing
+func serveIngestStatus(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to ingest status request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	status := GetIngestStatus()
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(status)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", status)
+	}
+}
+
+// Handles serving what multi-scouters disagreed on. Takes the event (defaulting to the current one) and match as query parameters.
+// Without a match, only the matches with disagreements are served.
+func serveDisagreements(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to disagreement request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	event := request.URL.Query().Get("event")
+	if event == "" {
+		event = GetCurrentEvent()
+	}
+
+	var match uint
+	if matchParam := request.URL.Query().Get("match"); matchParam != "" {
+		parsed, parseErr := strconv.ParseUint(matchParam, 10, 64)
+		if parseErr != nil {
+			writer.WriteHeader(400)
+			httpResponsef(writer, "Problem writing http response to disagreement request with a bad match", "Bad match number %v\n", matchParam)
+			return
+		}
+		match = uint(parsed)
+	}
+
+	reports := GetDisagreementReports(event, match)
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(reports)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", reports)
+	}
+}
+
+// Handles serving how reliable every scouter with a track record has been, most reliable first
+func serveScouterReliability(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to scouter reliability request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	reliabilities := GetScouterReliabilities()
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(reliabilities)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", reliabilities)
+	}
+}
+
+// Handles serving how writing to every output sink has been going
+func serveOutputSinks(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to output sink request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	statuses := GetSinkStatuses()
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(statuses)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", statuses)
+	}
+}
+
+// Handles serving the version history of one match and driverstation.
+// Takes the match, driverstation (red1..blue3) and replay query parameters, and optionally event.
+func serveEntryHistory(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to entry history request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	query := request.URL.Query()
+
+	event := query.Get("event")
+	if event == "" {
+		event = GetCurrentEvent()
+	}
+
+	match, parseErr := strconv.ParseUint(query.Get("match"), 10, 64)
+	if parseErr != nil {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to entry history request with a bad match", "Bad match number %v\n", query.Get("match"))
+		return
+	}
+
+	ds := query.Get("driverstation")
+	if _, _, valid := parseDSString(ds); !valid {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to entry history request with a bad driverstation", "Bad driverstation %v\n", ds)
+		return
+	}
+
+	history, historyErr := GetSlotHistory(event, MatchInfo{Number: uint(match), IsReplay: query.Get("replay") == "true"}, ds)
+	if historyErr != nil {
+		LogErrorf(historyErr, "Problem looking up history of %v match %v %v", event, match, ds)
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to unsuccessful entry history request", "Unable to look up history :(\n")
+		return
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(history)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", history)
+	}
+}
+
+// Handles serving every visit to a team's pit, and what they merge to on the sheet. Takes a team query parameter, and optionally an event.
+func servePitHistory(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to pit history request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	query := request.URL.Query()
+
+	event := query.Get("event")
+	if event == "" {
+		event = GetCurrentEvent()
+	}
+
+	team, parseErr := strconv.Atoi(query.Get("team"))
+	if parseErr != nil {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to pit history request with a bad team", "Bad team number %v\n", query.Get("team"))
+		return
+	}
+
+	history, historyErr := GetPitHistory(event, team)
+	if historyErr != nil {
+		LogErrorf(historyErr, "Problem merging pit visits of %v at %v", team, event)
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to unsuccessful pit history request", "Unable to merge pit visits :(\n")
+		return
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(writer).Encode(history)
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", history)
+	}
+}
+
+// A request to roll a match and driverstation back to an earlier version
+type entryRollbackRequest struct {
+	Event         string `json:"Event"`         // The event key. Optional, defaults to the current event.
+	Match         uint   `json:"Match"`         // The match number
+	Replay        bool   `json:"Replay"`        // If it is the replay of the match
+	DriverStation string `json:"DriverStation"` // The driverstation (red1..blue3)
+	Version       int    `json:"Version"`       // The version to roll back to
+}
+
+// Handles rolling a match and driverstation back to an earlier version, rewriting its row on the sheet
+func handleEntryRollback(writer http.ResponseWriter, request *http.Request) {
+	auth := getAuthFromCookies(request)
+	if !auth.IsAdmin() {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to rollback request with insufficient authentication", "Not authenticated :(")
+		return
+	}
+
+	var rollback entryRollbackRequest
+	if decodeErr := json.NewDecoder(request.Body).Decode(&rollback); decodeErr != nil {
+		LogErrorf(decodeErr, "Problem decoding %v", request.Body)
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to malformed rollback", "Malformed request :(\n")
+		return
+	}
+
+	if rollback.Event == "" {
+		rollback.Event = GetCurrentEvent()
+	}
+
+	match := MatchInfo{Number: rollback.Match, IsReplay: rollback.Replay}
+	version, rollbackErr := RollbackSlot(rollback.Event, match, rollback.DriverStation, rollback.Version, auth.Username)
+	if version == 0 && rollbackErr != nil {
+		writer.WriteHeader(400)
+		httpResponsef(writer, "Problem writing http response to unsuccessful rollback", "Unable to roll back: %v\n", rollbackErr)
+		return
+	}
+
+	if rollbackErr != nil {
+		writer.WriteHeader(500)
+		httpResponsef(writer, "Problem writing http response to rollback with a failed sheet write", "Rolled back as version %v, but the sheet couldn't be rewritten. Rebuild the sheet to fix it.\n", version)
+		return
+	}
+
+	httpResponsef(writer, "Problem writing http response to rollback", "Rolled back to version %v as version %v\n", rollback.Version, version)
+}
+
 // Handles serving the schedule for one scouter
 func serveScouterSchedule(writer http.ResponseWriter, request *http.Request) {
 	requestBytes, readErr := io.ReadAll(request.Body)
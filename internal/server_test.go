@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteSubmissionResponseCodes(t *testing.T) {
+	team := &TeamData{TeamNumber: 1816, SubmissionID: "not-a-uuid"}
+	team.Match.Number = 3
+	team.DriverStation.Number = 2
+
+	cases := map[string]struct {
+		result SubmissionResult
+		code   int
+	}{
+		"accepted":     {SubmissionResult{Status: SubmissionAccepted, File: "2026test_3_red2_1"}, 200},
+		"duplicate":    {SubmissionResult{Status: SubmissionDuplicate, File: "2026test_3_red2_1"}, 200},
+		"invalid uuid": {SubmitMatchEntry(team), 400},
+		"server fault": {SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}, 500},
+	}
+
+	for name, testCase := range cases {
+		recorder := httptest.NewRecorder()
+		writeSubmissionResponse(recorder, testCase.result)
+		if recorder.Code != testCase.code {
+			t.Errorf("%v: responded %v, want %v", name, recorder.Code, testCase.code)
+		}
+	}
+}
@@ -67,6 +67,21 @@ func TotalSetup(publicHosting bool) {
 		configs.CertsDirectory = filepath.Join(configs.RuntimeDirectory, DefaultCertsDirectory)
 	}
 
+	if configs.IngestWorkers <= 0 {
+		configs.IngestWorkers = kDefaultIngestWorkers
+	}
+
+	if configs.SheetsQuota <= 0 {
+		configs.SheetsQuota = kDefaultSheetsQuota
+	}
+
+	if configs.ReconcileMinutes == 0 {
+		configs.ReconcileMinutes = kDefaultReconcileMinutes
+	}
+
+	CheckMergeStrategies(configs.MergeStrategies, SeasonForEvent(configs.EventKey))
+	CheckSheetLayouts(configs.SheetLayouts, SeasonForEvent(configs.EventKey))
+
 	RSAPubKeyPath = filepath.Join(configs.RuntimeDirectory, "login-key.pub.pem")
 	RSAPrivateKeyPath = filepath.Join(configs.RuntimeDirectory, "login-key.pem")
 	DefaultPfpPath = filepath.Join(configs.PfpDirectory, DefaultPfp)
@@ -78,6 +93,7 @@ func TotalSetup(publicHosting bool) {
 	JsonErroredDirectory = filepath.Join(configs.JsonDirectory, "Errored")
 	JsonDiscardedDirectory = filepath.Join(configs.JsonDirectory, "Discarded")
 	JsonPitWrittenDirectory = filepath.Join(configs.JsonDirectory, "PitWritten")
+	JsonPrescoutedDirectory = filepath.Join(configs.JsonDirectory, "Prescouted")
 
 	// Essential Databases
 	configs.PathToDatabases = filepath.Join(configs.RuntimeDirectory, DefaultDbDirectory) //This is the only one i'm not having the user enter mainly because git cloning is uniform
@@ -85,9 +101,13 @@ func TotalSetup(publicHosting bool) {
 	LogMessage("Essential databases verified...")
 
 	// Sheets API
-	LogMessage("Ensuring sheets API...")
-	ensureSheetsAPI(configs)
-	LogMessage("Sheets API confirmed set-up")
+	if UsesSheetsSink(configs) {
+		LogMessage("Ensuring sheets API...")
+		ensureSheetsAPI(configs)
+		LogMessage("Sheets API confirmed set-up")
+	} else {
+		LogMessage("No output sink uses google sheets, skipping sheets API...")
+	}
 
 	// Sqlite
 	LogMessage("Ensuring sqlite3 driver...")
@@ -399,7 +419,7 @@ func recursiveEventKeyValidation(configs *GeneralConfigs, firstRun bool) (string
 }
 
 // Handles setting the event key. If the passed in key is valid, it will change the cached configs, the file-encoded configs, and trigger
-// writing to json, TeamLists, storing teams, and resetting user scores.
+// writing to json, TeamLists, storing teams, resetting user scores, and provisioning the spreadsheet.
 func SetEventKey(key string) bool {
 	file, openErr := OpenWithPermissions(ConfigFilePath)
 	if openErr != nil {
@@ -423,6 +443,9 @@ func SetEventKey(key string) bool {
 
 		ResetScores()
 
+		go ProvisionIfChanged()
+		MarkCoverageStale()
+
 		LogMessagef("Successfully changed Event Key to %v", key)
 
 		return true
@@ -440,6 +463,7 @@ func ensureInputtedJSON() {
 	HandleMkdirAll(JsonErroredDirectory)
 	HandleMkdirAll(JsonDiscardedDirectory)
 	HandleMkdirAll(JsonPitWrittenDirectory)
+	HandleMkdirAll(JsonPrescoutedDirectory)
 }
 
 // Moves all JSON files from Written to Archive upon changes to the event key
@@ -586,7 +610,13 @@ func ensureScoutDB(configs GeneralConfigs) {
 }
 
 // Checks for credentials.json, required for the sheets API. If it doesn't exist, it will exit the program.
+// The fake sheets backend needs no credentials, so it is set up straight away.
 func ensureSheetsAPI(configs GeneralConfigs) {
+	if configs.SheetsBackend == SheetsBackendFake {
+		SetupFakeSheetsAPI(configs.FakeSheetsFile, configs.SpreadSheetID)
+		return
+	}
+
 	creds, err := os.ReadFile(filepath.Join("conf", "credentials.json"))
 	if err != nil {
 		LogMessage("It appears there isn't a credentials.json file. Please follow the 'set up your environment' steps here: https://developers.google.com/sheets/api/quickstart/go#set_up_your_environment")
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Points the configs, the json directories and matches.db at a fresh temporary directory for one test
+func setupTestEnvironment(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	previous := CachedConfigs
+	t.Cleanup(func() {
+		matchDB.Close()
+		CachedConfigs = previous
+	})
+
+	CachedConfigs.RuntimeDirectory = dir
+	CachedConfigs.PathToDatabases = dir
+	CachedConfigs.SqliteDriver = "sqlite3"
+	CachedConfigs.EventKey = "2026test"
+	CachedConfigs.JsonDirectory = filepath.Join(dir, "json")
+
+	directories := map[*string]string{
+		&JsonInDirectory:         "In",
+		&JsonWrittenDirectory:    "Written",
+		&JsonMangledDirectory:    "Mangled",
+		&JsonArchiveDirectory:    "Archive",
+		&JsonErroredDirectory:    "Errored",
+		&JsonDiscardedDirectory:  "Discarded",
+		&JsonPitWrittenDirectory: "PitWritten",
+		&JsonPrescoutedDirectory: "Prescouted",
+	}
+	for directory, name := range directories {
+		*directory = filepath.Join(CachedConfigs.JsonDirectory, name)
+		if err := os.MkdirAll(*directory, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	InitMatchDB()
+	return dir
+}
+
+// Makes the passed in sink the only output sink for one test
+func setupTestSink(t *testing.T, sink OutputSink) {
+	t.Helper()
+
+	previous := currentSinks()
+	registered := &registeredSink{sink: sink, status: SinkStatus{Name: "test"}}
+	if background, ok := sink.(backgroundSink); ok {
+		background.reportTo(func(err error) { recordSinkResult(registered, err, false) })
+	}
+	outputSinks.mutex.Lock()
+	outputSinks.sinks = []*registeredSink{registered}
+	outputSinks.mutex.Unlock()
+	t.Cleanup(func() {
+		outputSinks.mutex.Lock()
+		outputSinks.sinks = previous
+		outputSinks.mutex.Unlock()
+	})
+}
+
+// An output sink that keeps every row written to it in memory
+type recordingSink struct {
+	mutex sync.Mutex
+	tabs  map[string]map[int][]interface{}
+}
+
+func (sink *recordingSink) WriteRows(tab string, rows []SinkRow) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	if sink.tabs == nil {
+		sink.tabs = make(map[string]map[int][]interface{})
+	}
+	if sink.tabs[tab] == nil {
+		sink.tabs[tab] = make(map[int][]interface{})
+	}
+	for _, row := range rows {
+		sink.tabs[tab][row.Row] = row.Values
+	}
+	return nil
+}
+
+func (sink *recordingSink) AppendRows(string, int, [][]interface{}) error { return nil }
+
+func (sink *recordingSink) ClearTabs(tabs []string) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	for _, tab := range tabs {
+		delete(sink.tabs, tab)
+	}
+	return nil
+}
+
+// Gets how a row reads, or nil if it was never written
+func (sink *recordingSink) row(tab string, row int) []string {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	values, ok := sink.tabs[tab][row]
+	if !ok {
+		return nil
+	}
+	return cellTexts(values)
+}
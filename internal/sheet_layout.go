@@ -0,0 +1,248 @@
+package internal
+
+// Sheet layouts from the config, so columns can be added, removed and moved around without touching code
+
+import (
+	"slices"
+	"strings"
+)
+
+// One column of a tab
+type SheetColumn struct {
+	Header string `yaml:"Header"` // What goes in the header row
+	Field  string `yaml:"Field"`  // The json path of the value in the entry, like auto.scores
+	Format string `yaml:"Format"` // The name of a formatter the season provides, like GetAutoAccuracy. Used instead of Field if set.
+}
+
+// The columns of each tab, by tab name
+type SheetLayouts map[string][]SheetColumn
+
+// The tabs that can be laid out in the config
+var layoutTabs = []string{TabRawData, TabPitScouting, TabPrescouting}
+
+// Every tab the server writes to
+var sheetTabs = []string{TabRawData, TabPitScouting, TabPrescouting, TabCoverage}
+
+// Cells every season can use on the RawData and Prescouting tabs, on top of its own formatters
+var commonMatchFormatters = map[string]MatchFormatter{
+	"GetDSString": func(entries []MatchEntry, _ any) interface{} {
+		ds := entries[0].Info().DriverStation
+		return GetDSString(ds.IsBlue, uint(ds.Number))
+	},
+	"Scouters": func(entries []MatchEntry, _ any) interface{} {
+		var scouters []string
+		for _, entry := range entries {
+			scouters = append(scouters, entry.Info().Scouter)
+		}
+		return strings.Join(scouters, ", ")
+	},
+}
+
+// Gets a named formatter for match tabs, looking at the season's own ones first
+func (season *GameSeason) matchFormatter(name string) (MatchFormatter, bool) {
+	if formatter, ok := season.MatchFormatters[name]; ok {
+		return formatter, true
+	}
+	formatter, ok := commonMatchFormatters[name]
+	return formatter, ok
+}
+
+// Gets the layout configured for a tab, returning false if it keeps the season's built-in columns
+func sheetLayout(tab string) ([]SheetColumn, bool) {
+	layout, ok := CachedConfigs.SheetLayouts[tab]
+	return layout, ok && len(layout) > 0 && slices.Contains(layoutTabs, tab)
+}
+
+// Logs anything in the configured sheet layouts that can't be written, so typos in the config don't go unnoticed
+func CheckSheetLayouts(configured SheetLayouts, season *GameSeason) {
+	for tab, layout := range configured {
+		if !slices.Contains(layoutTabs, tab) {
+			LogMessagef("Unknown tab %v in SheetLayouts, it will be ignored", tab)
+			continue
+		}
+
+		for i, column := range layout {
+			if column.Format == "" {
+				if column.Field == "" {
+					LogMessagef("Column %v of %v has neither a Field nor a Format, it will be left empty", i+1, tab)
+				}
+				continue
+			}
+
+			known := season.PitFormatters[column.Format] != nil
+			if tab != TabPitScouting {
+				_, known = season.matchFormatter(column.Format)
+			}
+			if !known {
+				LogMessagef("Unknown formatter %v for column %v of %v in %v, it will be left empty", column.Format, i+1, tab, season.Name)
+			}
+		}
+	}
+}
+
+// Builds the RawData row of one entry, or of several merged entries of the same match and driverstation
+func (season *GameSeason) RawDataRow(entries ...MatchEntry) []interface{} {
+	if layout, ok := sheetLayout(TabRawData); ok {
+		return season.layoutMatchRow(layout, entries)
+	}
+
+	if len(entries) == 1 {
+		return season.MatchRow(entries[0])
+	}
+	return season.MergedRow(entries)
+}
+
+// Builds the Prescouting row of a prescouted entry
+func (season *GameSeason) PrescoutingRow(entry MatchEntry) []interface{} {
+	if layout, ok := sheetLayout(TabPrescouting); ok {
+		return season.layoutMatchRow(layout, []MatchEntry{entry})
+	}
+	return season.PrescoutRow(entry)
+}
+
+// Builds the PitScouting row of a pit entry
+func (season *GameSeason) PitScoutingRow(pit PitEntry) []interface{} {
+	layout, ok := sheetLayout(TabPitScouting)
+	if !ok {
+		return season.PitRow(pit)
+	}
+
+	var values []interface{}
+	fields := flattenEntry(pit)
+	for _, column := range layout {
+		if column.Format != "" {
+			if formatter, exists := season.PitFormatters[column.Format]; exists {
+				values = append(values, formatter(pit))
+			} else {
+				values = append(values, "")
+			}
+			continue
+		}
+
+		values = append(values, cellValue(fields[column.Field]))
+	}
+	return values
+}
+
+// Builds a row of a match tab following a layout
+func (season *GameSeason) layoutMatchRow(layout []SheetColumn, entries []MatchEntry) []interface{} {
+	var flattened []map[string]any
+	for _, entry := range entries {
+		flattened = append(flattened, flattenEntry(entry))
+	}
+
+	var compiled any
+	if season.CompileRow != nil && slices.ContainsFunc(layout, func(column SheetColumn) bool { return column.Format != "" }) {
+		compiled = season.CompileRow(entries)
+	}
+
+	var values []interface{}
+	for _, column := range layout {
+		if column.Format != "" {
+			if formatter, exists := season.matchFormatter(column.Format); exists {
+				values = append(values, formatter(entries, compiled))
+			} else {
+				values = append(values, "")
+			}
+			continue
+		}
+
+		values = append(values, mergedFieldValue(column.Field, entries, flattened))
+	}
+	return values
+}
+
+// Gets the value of a field for a row. Merged rows combine every scouter's answer with the merge strategy configured for the field,
+// falling back to the mean for numbers, any for booleans and the mode for text.
+func mergedFieldValue(field string, entries []MatchEntry, flattened []map[string]any) interface{} {
+	if len(flattened) == 1 {
+		return cellValue(flattened[0][field])
+	}
+
+	var scouters []string
+	var numbers []float64
+	var bools []bool
+	var texts []string
+	for i, values := range flattened {
+		scouters = append(scouters, entries[i].Info().Scouter)
+		switch value := values[field].(type) {
+		case float64:
+			numbers = append(numbers, value)
+		case bool:
+			bools = append(bools, value)
+		case string:
+			texts = append(texts, value)
+		}
+	}
+
+	merger := newFieldMerger(scouters)
+	switch {
+	case len(numbers) == len(flattened):
+		return merger.Number(field, numbers, MergeMean)
+	case len(bools) == len(flattened):
+		return merger.Bool(field, bools, MergeAny)
+	case len(texts) == len(flattened):
+		text, _ := merger.String(field, texts, MergeMode)
+		return text
+	}
+
+	// Missing from some entries or different kinds of answers, so there's nothing sensible to merge
+	return cellValue(flattened[0][field])
+}
+
+// Turns a missing field into an empty cell
+func cellValue(value any) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// Gets the header row of a tab
+func (season *GameSeason) Headers(tab string) []interface{} {
+	var headers []interface{}
+	if layout, ok := sheetLayout(tab); ok {
+		for _, column := range layout {
+			headers = append(headers, column.Header)
+		}
+	} else {
+		var columns []string
+		switch tab {
+		case TabRawData:
+			columns = season.MatchColumns
+		case TabPrescouting:
+			columns = season.PrescoutCols
+		case TabPitScouting:
+			columns = season.PitColumns
+		case TabCoverage:
+			columns = coverageColumns
+		}
+		for _, column := range columns {
+			headers = append(headers, column)
+		}
+	}
+
+	// Merged rows are flagged in the cell after them
+	if tab == TabRawData && CachedConfigs.UsingMultiScouting {
+		headers = append(headers, "Disagreements")
+	}
+
+	return headers
+}
+
+// Writes the header row of every tab for the current season to every output sink, all at once
+func WriteSheetHeaders() {
+	season := CurrentSeason()
+
+	err := writeToSinks(func(sink OutputSink) error {
+		for _, tab := range sheetTabs {
+			if err := sink.WriteRows(tab, []SinkRow{{Row: 1, Values: season.Headers(tab)}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		LogError(err, "Problem writing the headers")
+	}
+}
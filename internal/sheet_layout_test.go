@@ -0,0 +1,34 @@
+package internal
+
+import "testing"
+
+func TestLayoutRowsCompileOnce(t *testing.T) {
+	setupTestEnvironment(t)
+	InitUserDB()
+	t.Cleanup(func() { userDB.Close() })
+
+	CachedConfigs.SheetLayouts = SheetLayouts{TabRawData: {
+		{Header: "Match", Field: "match.number"},
+		{Header: "Collection", Format: "GetCollection"},
+		{Header: "Auto Accuracy", Format: "GetAutoAccuracy"},
+		{Header: "Notes", Format: "CompileNotes"},
+	}}
+
+	season := *SeasonForEvent("2026test")
+	compiles := 0
+	season.CompileRow = func(entries []MatchEntry) any {
+		compiles++
+		return compile2026Row(entries)
+	}
+
+	first := &TeamData{TeamNumber: 1816, Match: MatchInfo{Number: 5}, Scouter: "a"}
+	second := &TeamData{TeamNumber: 1816, Match: MatchInfo{Number: 5}, Scouter: "b"}
+	row := season.RawDataRow(first, second)
+
+	if compiles != 1 {
+		t.Errorf("compiled the row %v times, want once", compiles)
+	}
+	if want := CompileNotes2(CompileMultiMatch(*first, *second)); row[3] != want {
+		t.Errorf("notes = %#v, want %#v", row[3], want)
+	}
+}
@@ -5,10 +5,12 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	"os"
+	"slices"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -124,88 +126,39 @@ func SetupSheetsAPI(creds []byte) {
 	LogMessagef("Using SpreadsheetId: %v", CachedConfigs.SpreadSheetID)
 }
 
-// Writes team data from multi-scouting to a specified line
-func WriteMultiScoutedTeamDataToLine(matchdata MultiMatch, row int, sources []TeamData) bool { // TODO: FIX FOR NEW
-	// troughTendency, L2Tendency, L3Tendency, L4Tendency, processorTendency, netTendency, knockTendency, shuttleTendency := GetCycleTendencies(matchdata.CycleData.AllCycles)
-	// troughAccuracy, L2Accuracy, L3Accuracy, L4Accuracy, processorAccuracy, netAccuracy, knockAccuracy, shuttleAccuracy := GetCycleAccuracies(matchdata.CycleData.AllCycles)
+// Writes entries from multi-scouting, merged by the rules of their season, to a specified line, returning an error if no output sink could be written to.
+// Rows the scouters disagreed on are flagged in the cell after the merged values, and the merge is stored with its disagreement report.
+func WriteMultiScoutedTeamDataToLine(season *GameSeason, event string, entries []MatchEntry, row int) error {
+	merged, report := mergeSlot(season, event, entries)
 
-	// This is ONE ROW. Each value is a cell in that row.
-	valuesToWrite := []interface{}{
-		GetDSString(matchdata.DriverStation.IsBlue, uint(matchdata.DriverStation.Number)),
-		matchdata.Match,
-		matchdata.TeamNumber,
-		matchdata.CycleData.AvgCycleTime,
-		matchdata.CycleData.NumCycles,
-		TurnAutoFieldIntoAnAwesomeAndReadableString(matchdata.Auto.Field),
-		matchdata.Auto.CanAuto,            // Had Auto
-		matchdata.Auto.HangAuto,           // Had Auto
-		matchdata.Auto.WonAuto,            // Had Auto
-		matchdata.Auto.Scores,             // Scores in auto
-		GetAutoAccuracy(matchdata.Auto),   // Auto accuracy
-		matchdata.Auto.Ejects,             // Auto shuttles
-		matchdata.Parked,                  // Parked
-		CompileNotes2(matchdata, sources), // Notes + Penalties + DC + Lost track
+	if err := WriteSinkRows(TabRawData, []SinkRow{{Row: row, Values: merged}}); err != nil {
+		return err
 	}
 
-	var vr sheets.ValueRange
-
-	vr.Values = append(vr.Values, valuesToWrite)
-
-	writeRange := fmt.Sprintf("RawData!B%v", row)
-
-	_, err := Srv.Spreadsheets.Values.Update(SpreadsheetId, writeRange, &vr).ValueInputOption("RAW").Do()
-
-	if err != nil {
-		LogError(err, "Unable to write data to sheet")
-		return false
-	}
-	return true
+	StoreMergeResult(merged, report)
+	return nil
 }
 
-// Writes data from a single-scouted match to a line
-func WriteTeamDataToLine(teamData TeamData, row int) bool { // TODO: FIX FOR NEW
-	// This is ONE ROW. Each value is a cell in that row.
-	valuesToWrite := []interface{}{
-		GetDSString(teamData.DriverStation.IsBlue, uint(teamData.DriverStation.Number)),
-		teamData.Match.Number, // Match Number
-		teamData.TeamNumber,   // Team Number
-		// GetAvgCycleTime(teamData.Cycles), // Avg cycle time
-		// GetNumCycles(teamData.Cycles),    // Num Cycles
-		GetCollection(teamData.Teleop.Collection),
-		TurnAutoFieldIntoAnAwesomeAndReadableString(teamData.Auto.Field),
-		teamData.Auto.CanAuto,                                     // Had Auto
-		teamData.Auto.HangAuto,                                    // Had Hanging Auto
-		teamData.Auto.WonAuto,                                     // Won Auto
-		teamData.Auto.Scores,                                      // Scores in auto
-		GetAutoAccuracy(teamData.Auto),                            // Auto accuracy
-		fmt.Sprintf("%v%%", teamData.Auto.Accuracy.HPAccuracy),    // Accuracy of Human
-		fmt.Sprintf("%v%%", teamData.Auto.Accuracy.RobotAccuracy), // Accuracy of Robot
-		teamData.Auto.Ejects,                                      // Auto shuttles
-		teamData.Endgame.ClimbTimer,                               // Climb Time
-		teamData.Endgame.Park,                                     // Parked
-		GetStyleString(teamData.Teleop),
-		CompileNotes(teamData), // Notes + Penalties + DC + Lost track
-	}
-
-	var vr sheets.ValueRange
-
-	vr.Values = append(vr.Values, valuesToWrite)
-
-	writeRange := fmt.Sprintf("RawData!B%v", row)
-
-	_, err := Srv.Spreadsheets.Values.Append(SpreadsheetId, writeRange, &vr).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do()
-
-	if err != nil {
-		LogError(err, "Unable to write data to sheet")
-		return false
-	}
-
-	return true
+// Writes data from a single-scouted match after the last line with data, starting the search at the passed in line.
+// Returns an error if no output sink could be written to.
+func WriteTeamDataToLine(season *GameSeason, entry MatchEntry, row int) error {
+	return AppendSinkRows(TabRawData, row, [][]interface{}{season.RawDataRow(entry)})
+}
 
+// Writes data from a single match entry over a line, returning an error if no output sink could be written to.
+// Unlike WriteTeamDataToLine(), this replaces whatever was on that line instead of appending after it,
+// including the flag cell of a merged row.
+func WriteTeamDataToRow(season *GameSeason, entry MatchEntry, row int) error {
+	return WriteSinkRows(TabRawData, []SinkRow{{Row: row, Values: append(season.RawDataRow(entry), "")}})
 }
 
-// Wrapper around sheets' batch update.
+// Wrapper around sheets' batch update. Waits for room in the quota, and retries if google says to slow down.
 func BatchUpdate(dataset [][]interface{}, writeRange string) {
+	if Srv == nil {
+		LogMessage("Not writing to the sheet, as the sheets API isn't set up")
+		return
+	}
+
 	rb := &sheets.BatchUpdateValuesRequest{
 		ValueInputOption: "USER_ENTERED",
 	}
@@ -215,7 +168,10 @@ func BatchUpdate(dataset [][]interface{}, writeRange string) {
 		Values: dataset,
 	})
 
-	_, err := Srv.Spreadsheets.Values.BatchUpdate(SpreadsheetId, rb).Do()
+	err := callSheets(func() error {
+		_, err := Srv.Spreadsheets.Values.BatchUpdate(SpreadsheetId, rb).Do()
+		return err
+	})
 
 	if err != nil {
 		LogError(err, "Unable to write data to sheet")
@@ -242,25 +198,14 @@ func FillMatches(startMatch int, endMatch int) {
 	}
 }
 
-// Updates the ID of the sheet to be used, in memory and yaml.
+// Updates the ID of the sheet to be used, in memory and yaml, then provisions it in the background.
 func UpdateSheetID(newSheet string) string {
 	if IsSheetValid(newSheet) {
-		CachedConfigs.SpreadSheetID = newSheet
-
-		configFile, openErr := OpenWithPermissions(ConfigFilePath)
-		if openErr != nil {
-			LogErrorf(openErr, "Problem opening %v", ConfigFilePath)
+		if saveErr := saveSheetID(newSheet); saveErr != nil {
 			return "There was a problem updating the sheet ID"
 		}
 
-		defer configFile.Close()
-
-		encodeErr := yaml.NewEncoder(configFile).Encode(&CachedConfigs)
-
-		if encodeErr != nil {
-			LogErrorf(encodeErr, "Problem encoding %v", CachedConfigs)
-			return "There was a problem updating the sheet ID"
-		}
+		go ProvisionIfChanged()
 
 		return "Successfully updated sheet ID to " + newSheet
 	}
@@ -268,8 +213,35 @@ func UpdateSheetID(newSheet string) string {
 
 }
 
+// Switches to another spreadsheet, in memory and yaml
+func saveSheetID(newSheet string) error {
+	CachedConfigs.SpreadSheetID = newSheet
+	SpreadsheetId = newSheet
+
+	configFile, openErr := OpenWithPermissions(ConfigFilePath)
+	if openErr != nil {
+		LogErrorf(openErr, "Problem opening %v", ConfigFilePath)
+		return openErr
+	}
+
+	defer configFile.Close()
+
+	encodeErr := yaml.NewEncoder(configFile).Encode(&CachedConfigs)
+
+	if encodeErr != nil {
+		LogErrorf(encodeErr, "Problem encoding %v", CachedConfigs)
+		return encodeErr
+	}
+
+	return nil
+}
+
 // Tries to read the top-left cell of the RawData tab, returning if it can.
 func IsSheetValid(id string) bool {
+	if Srv == nil {
+		return false
+	}
+
 	spreadsheetId := id
 	readRange := "RawData!A1:1"
 	_, err := Srv.Spreadsheets.Values.Get(spreadsheetId, readRange).Do()
@@ -282,6 +254,10 @@ func IsSheetValid(id string) bool {
 // Adds conditinoal formatting to the raw data tab.
 // This consists of two sinusoidal functions that ensure 3-red 3-blue coloring.
 func WriteConditionalFormatting() {
+	if Srv == nil {
+		return
+	}
+
 	tabs, err := Srv.Spreadsheets.Get(SpreadsheetId).Do()
 	if err != nil {
 		LogError(err, "Failed to get tabs")
@@ -372,83 +348,175 @@ func WriteConditionalFormatting() {
 	}
 }
 
-// Writes data from pit scouting to a line
-func WritePitDataToLine(pitData PitScoutingData, row int) bool {
+// Writes data from pit scouting over a team's line, returning an error if no output sink could be written to
+func WritePitDataToLine(season *GameSeason, pit PitEntry, row int) error {
+	return WriteSinkRows(TabPitScouting, []SinkRow{{Row: row, Values: season.PitScoutingRow(pit)}})
+}
 
-	// This is ONE ROW. Each value is a cell in that row.
-	valuesToWrite := []interface{}{
-		pitData.TeamNumber,  //Team Number
-		pitData.Scouter,     //Person/people who pit scouted
-		pitData.Weight,      //The weight of the robot
-		pitData.AutoNum,     //The number of autos they have
-		pitData.Dynamic,     //Whether they have dynamic autos
-		pitData.Drivetrain,  //The type of drivetrain
-		pitData.GearRatio,   //The GearRatio on the top of my head
-		pitData.Coral,       //The position(s) their robot is able to score
-		pitData.Algae,       //The position(s) their robot is able to score
-		pitData.AlgaeGround, //Whether it can collect algae from the ground
-		pitData.AlgaeSource, //Whether it can collect algae from the source
-		// pitData.Cycle,               //Their cycle time
-		pitData.Experience,          //The driver's experience
-		pitData.Teleop,              //The strategy for teleop??
-		pitData.Endgame,             //The strategy for endgame
-		pitData.Shallow,             //Whether it can shallow climb
-		pitData.Deep,                //Whether it can deep climb
-		pitData.RobotTypeCompliment, //What part of the robot compliments you?
-		pitData.FavoritePart,        //Favortite part of the robot
-		pitData.Notes,               //Other Notes
+// Writes data from a prescouted match to a line, returning an error if no output sink could be written to
+func WritePrescoutDataToLine(season *GameSeason, entry MatchEntry, row int) error {
+	return WriteSinkRows(TabPrescouting, []SinkRow{{Row: row, Values: season.PrescoutingRow(entry)}})
+}
 
+// A merged row along with the disagreement report behind it
+type mergedRow struct {
+	values []interface{}
+	report DisagreementReport
+}
+
+// Works out what the RawData, PitScouting and Prescouting tabs of an event should have on them, headers included, from what is stored in matches.db.
+// When multi-scouting, every match slot is merged and goes on its own row. Otherwise, every entry goes on the row it was first written to.
+// Also returns every merge that was made, so it can be stored. Fails if anything can't be read, rather than leave it out.
+func expectedSheetRows(event string) (map[string][]SinkRow, []mergedRow, error) {
+	season := SeasonForEvent(event)
+
+	matchRows := []SinkRow{{Row: 1, Values: season.Headers(TabRawData)}}
+	var merges []mergedRow
+
+	entries, err := GetStoredEntries(event, EntryWritten)
+	if err != nil {
+		return nil, nil, err
 	}
+	if CachedConfigs.UsingMultiScouting {
+		var rows []int
+		slots := make(map[int][]MatchEntry)
+		for _, entry := range entries {
+			row := GetRow(entry.Data.Info())
+			if _, ok := slots[row]; !ok {
+				rows = append(rows, row)
+			}
+			slots[row] = append(slots[row], entry.Data)
+		}
 
-	var vr sheets.ValueRange
+		for _, row := range rows {
+			slot := slots[row]
 
-	vr.Values = append(vr.Values, valuesToWrite)
+			// Replays share a row with the original match, and take precedence over it
+			var replays []MatchEntry
+			for _, entry := range slot {
+				if entry.Info().Match.IsReplay {
+					replays = append(replays, entry)
+				}
+			}
+			if len(replays) > 0 {
+				slot = replays
+			}
 
-	writeRange := fmt.Sprintf("PitScouting!B%v", row)
+			var values []interface{}
+			if len(slot) == 1 {
+				values = season.RawDataRow(slot[0])
+			} else {
+				var report DisagreementReport
+				values, report = mergeSlot(season, event, slot)
+				merges = append(merges, mergedRow{values: values, report: report})
+			}
 
-	_, err := Srv.Spreadsheets.Values.Append(SpreadsheetId, writeRange, &vr).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do()
+			matchRows = append(matchRows, SinkRow{Row: row, Values: values})
+		}
+	} else {
+		for _, entry := range entries { // Entries written before rows were kept get the next free rows, in match order
+			row, rowErr := GetEntryRow(event, entry.File)
+			if rowErr != nil {
+				return nil, nil, rowErr
+			}
+			matchRows = append(matchRows, SinkRow{Row: row, Values: season.RawDataRow(entry.Data)})
+		}
+	}
 
+	// Every visit to a team's pit is merged onto one row
+	pitEntries, err := GetStoredPitEntries(event, EntryWritten)
 	if err != nil {
-		LogError(err, "Unable to write data to sheet")
-		return false
+		return nil, nil, err
+	}
+	var pitTeams []int
+	for _, entry := range pitEntries {
+		if !slices.Contains(pitTeams, entry.Data.Team()) {
+			pitTeams = append(pitTeams, entry.Data.Team())
+		}
 	}
 
-	return true
+	pitRows := []SinkRow{{Row: 1, Values: season.Headers(TabPitScouting)}}
+	for _, team := range pitTeams {
+		row, rowErr := GetTeamRow(event, TabPitScouting, team)
+		merged, mergeErr := mergedTeamPit(season, event, team, StoredPitEntry{})
+		if err := errors.Join(rowErr, mergeErr); err != nil {
+			return nil, nil, err
+		}
+		pitRows = append(pitRows, SinkRow{Row: row, Values: season.PitScoutingRow(merged)})
+	}
 
-}
+	// Only the newest prescouting of each team is on the sheet
+	newest := make(map[int]MatchEntry)
+	prescoutEntries, err := GetStoredPrescoutEntries(event, EntryWritten)
+	if err != nil {
+		return nil, nil, err
+	}
+	var teams []int
+	for _, entry := range prescoutEntries {
+		team := int(entry.Data.Info().TeamNumber)
+		if _, ok := newest[team]; !ok {
+			teams = append(teams, team)
+		}
+		newest[team] = entry.Data
+	}
 
-// Writes data from a prescouted match to a line
-func WritePrescoutDataToLine(teamData TeamData, row int) bool { // TODO: FIX FOR NEW
+	prescoutRows := []SinkRow{{Row: 1, Values: season.Headers(TabPrescouting)}}
+	for _, team := range teams {
+		row, err := GetTeamRow(event, TabPrescouting, team)
+		if err != nil {
+			return nil, nil, err
+		}
+		prescoutRows = append(prescoutRows, SinkRow{Row: row, Values: season.PrescoutingRow(newest[team])})
+	}
 
-	// This is ONE ROW. Each value is a cell in that row.
-	valuesToWrite := []interface{}{
-		GetDSString(teamData.DriverStation.IsBlue, uint(teamData.DriverStation.Number)),
-		teamData.TeamNumber, // Team Number
-		// GetAvgCycleTime(teamData.Cycles), // Avg cycle time
-		// GetNumCycles(teamData.Cycles), // Num Cycles
+	return map[string][]SinkRow{TabRawData: matchRows, TabPitScouting: pitRows, TabPrescouting: prescoutRows}, merges, nil
+}
 
-		teamData.Auto.CanAuto,          // Had Auto
-		teamData.Auto.Scores,           // Scores in auto
-		GetAutoAccuracy(teamData.Auto), // Auto accuracy
-		teamData.Auto.Ejects,           // Auto shuttles
-		teamData.Endgame.ClimbTimer,    // Climb Time
-		// GetParkStatus(teamData.Endgame),           // Parked
-		CompileNotes(teamData), // Notes + Penalties + DC + Lost track
+// Rewrites the RawData, PitScouting and Prescouting tabs of the current event, headers included, from what is stored in matches.db, in the passed in output sink or every one if it is empty.
+func RebuildSheetFromDB(sinkName string) error {
+	rows, merges, err := expectedSheetRows(GetCurrentEvent())
+	if err != nil {
+		return fmt.Errorf("unable to read matches.db: %w", err)
+	}
+	for _, merge := range merges {
+		StoreMergeResult(merge.values, merge.report)
 	}
 
-	var vr sheets.ValueRange
+	var errs []error
+	rebuilt := 0
+	for _, registered := range currentSinks() {
+		if sinkName != "" && registered.status.Name != sinkName {
+			continue
+		}
+		rebuilt++
 
-	vr.Values = append(vr.Values, valuesToWrite)
+		err := registered.sink.ClearTabs(layoutTabs)
+		for _, tab := range layoutTabs {
+			if err == nil {
+				err = registered.sink.WriteRows(tab, rows[tab])
+			}
+		}
+		if background, ok := registered.sink.(backgroundSink); ok && err == nil {
+			err = background.Flush()
+		}
 
-	writeRange := fmt.Sprintf("Prescouting!B%v", row)
+		recordSinkResult(registered, err, true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", registered.status.Name, err))
+		}
+	}
 
-	_, err := Srv.Spreadsheets.Values.Update(SpreadsheetId, writeRange, &vr).ValueInputOption("RAW").Do()
+	// Coverage isn't rebuilt from matches.db, but whatever was on it gets written again in case it was messed up too
+	resetCoverage()
 
-	if err != nil {
-		LogError(err, "Unable to write data to sheet")
-		return false
+	if rebuilt == 0 {
+		return errors.New("no output sink named " + sinkName)
 	}
 
-	return true
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 
+	LogMessagef("Rebuilt %v output sink(s) with %v RawData rows, %v PitScouting rows and %v Prescouting rows", rebuilt, len(rows[TabRawData])-1, len(rows[TabPitScouting])-1, len(rows[TabPrescouting])-1)
+	return nil
 }
@@ -0,0 +1,305 @@
+package internal
+
+// The google sheets output sink. Writes are buffered and sent in batches in the background, staying under the sheets API's
+// per-minute quota and backing off when google says to slow down, so ingestion never waits on the sheet.
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Google's default limit of write requests per minute per user
+const kDefaultSheetsQuota = 60
+
+// How often buffered writes are sent
+const kSheetsFlushInterval = 2 * time.Second
+
+// How many times a call is tried before it is given up on
+const kSheetsMaxAttempts = 8
+
+// The wait before the first retry, doubled after every failed attempt
+const kSheetsInitialBackoff = time.Second
+
+// The longest wait between retries
+const kSheetsMaxBackoff = time.Minute
+
+// What a buffered write does
+type sheetsOpKind int
+
+// Buffered write kind enum
+const (
+	sheetsWrite  sheetsOpKind = iota // Writes over rows
+	sheetsAppend                     // Appends rows after a table
+	sheetsClear                      // Clears tabs
+)
+
+// One write waiting to be sent
+type sheetsOp struct {
+	kind   sheetsOpKind
+	tab    string          // The tab, for writes and appends
+	rows   []SinkRow       // The rows, for writes
+	from   int             // Where the table starts, for appends
+	values [][]interface{} // The rows, for appends
+	tabs   []string        // The tabs, for clears
+}
+
+// One call to the sheets API made out of buffered writes
+type sheetsCall struct {
+	do  func() error
+	ops []sheetsOp // The writes that went into it
+}
+
+// Keeps calls to the sheets API under the per-minute quota
+type sheetsQuota struct {
+	mutex sync.Mutex
+	calls []time.Time // When every call in the last minute was made
+}
+
+// Every call to the sheets API, held in memory
+var sheetsCalls sheetsQuota
+
+// Blocks until another call fits in the quota, then counts it. The fake sheets backend has no quota.
+func (quota *sheetsQuota) wait() {
+	if CachedConfigs.SheetsBackend == SheetsBackendFake {
+		return
+	}
+
+	limit := CachedConfigs.SheetsQuota
+	if limit <= 0 {
+		limit = kDefaultSheetsQuota
+	}
+
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	for {
+		cutoff := time.Now().Add(-time.Minute)
+		for len(quota.calls) > 0 && quota.calls[0].Before(cutoff) {
+			quota.calls = quota.calls[1:]
+		}
+
+		if len(quota.calls) < limit {
+			quota.calls = append(quota.calls, time.Now())
+			return
+		}
+
+		time.Sleep(time.Until(quota.calls[0].Add(time.Minute)))
+	}
+}
+
+// Returns if a failed call is worth trying again: google asking to slow down, being unavailable, or the network dropping
+func isRetryableSheetsError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 429 || apiErr.Code == 503
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// Makes a call to the sheets API within the quota, backing off and retrying while it fails in a way worth retrying
+func callSheets(call func() error) error {
+	backoff := kSheetsInitialBackoff
+
+	var err error
+	for attempt := 1; attempt <= kSheetsMaxAttempts; attempt++ {
+		sheetsCalls.wait()
+
+		err = call()
+		if err == nil || !isRetryableSheetsError(err) {
+			return err
+		}
+
+		LogMessagef("Sheets API call failed (attempt %v of %v), waiting %v: %v", attempt, kSheetsMaxAttempts, backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, kSheetsMaxBackoff)
+	}
+
+	return err
+}
+
+// Writes to the google sheet through the sheets API, buffering writes and sending them in batches in the background
+type sheetsSink struct {
+	mutex   sync.Mutex
+	pending []sheetsOp // Writes waiting to be sent, oldest first
+	writing sync.Mutex // Held while buffered writes are being sent, so they go out in order
+	report  func(err error)
+	stop    chan struct{} // Closed to stop sending writes in the background
+}
+
+// Creates the sheets sink and starts sending its writes
+func newSheetsSink() *sheetsSink {
+	sink := &sheetsSink{report: func(error) {}, stop: make(chan struct{})}
+
+	go func() {
+		ticker := time.NewTicker(kSheetsFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sink.Flush()
+			case <-sink.stop:
+				return
+			}
+		}
+	}()
+
+	return sink
+}
+
+// Stops sending writes in the background. Anything still buffered is only sent by calling Flush.
+func (sink *sheetsSink) Stop() {
+	close(sink.stop)
+}
+
+// Sets what is told how every batch of writes went
+func (sink *sheetsSink) reportTo(report func(err error)) {
+	sink.report = report
+}
+
+// Queues a write to be sent with the next batch
+func (sink *sheetsSink) queue(op sheetsOp) {
+	sink.mutex.Lock()
+	sink.pending = append(sink.pending, op)
+	sink.mutex.Unlock()
+}
+
+// Queues writing over the passed in rows
+func (sink *sheetsSink) WriteRows(tab string, rows []SinkRow) error {
+	sink.queue(sheetsOp{kind: sheetsWrite, tab: tab, rows: rows})
+	return nil
+}
+
+// Queues appending rows after the table that the from row is in
+func (sink *sheetsSink) AppendRows(tab string, from int, values [][]interface{}) error {
+	sink.queue(sheetsOp{kind: sheetsAppend, tab: tab, from: from, values: values})
+	return nil
+}
+
+// Queues clearing everything from B2 onwards, leaving the headers and the match numbers in column A
+func (sink *sheetsSink) ClearTabs(tabs []string) error {
+	sink.queue(sheetsOp{kind: sheetsClear, tabs: tabs})
+	return nil
+}
+
+// Gets how many writes are waiting to be sent
+func (sink *sheetsSink) Pending() int {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	return len(sink.pending)
+}
+
+// Sends everything buffered, returning the last error if any call failed.
+// Writes that fail for good are dropped and reported, which marks the sink stale until a reconciliation or rebuild catches it up.
+func (sink *sheetsSink) Flush() error {
+	sink.writing.Lock()
+	defer sink.writing.Unlock()
+
+	sink.mutex.Lock()
+	ops := sink.pending
+	sink.pending = nil
+	sink.mutex.Unlock()
+
+	var lastErr error
+	for _, call := range coalesceSheetsOps(ops) {
+		err := callSheets(call.do)
+		sink.report(err)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// Combines buffered writes into as few calls as possible without changing what order they happen in.
+// Runs of row writes become one batch update, runs of appends to the same place become one append, and runs of clears become one batch clear.
+func coalesceSheetsOps(ops []sheetsOp) []sheetsCall {
+	var calls []sheetsCall
+
+	for i := 0; i < len(ops); {
+		j := i + 1
+		for j < len(ops) && ops[j].kind == ops[i].kind &&
+			(ops[i].kind != sheetsAppend || (ops[j].tab == ops[i].tab && ops[j].from == ops[i].from)) {
+			j++
+		}
+		run := ops[i:j]
+		i = j
+
+		switch run[0].kind {
+		case sheetsWrite:
+			calls = append(calls, sheetsCall{do: batchWriteCall(run), ops: run})
+		case sheetsAppend:
+			calls = append(calls, sheetsCall{do: appendCall(run), ops: run})
+		case sheetsClear:
+			calls = append(calls, sheetsCall{do: clearCall(run), ops: run})
+		}
+	}
+
+	return calls
+}
+
+// Builds one batch update out of a run of row writes. If a row is written more than once, only the last write is sent.
+func batchWriteCall(run []sheetsOp) func() error {
+	var data []*sheets.ValueRange
+	written := make(map[string]int)
+
+	for _, op := range run {
+		for _, row := range op.rows {
+			writeRange := fmt.Sprintf("%v!B%v", op.tab, row.Row)
+			valueRange := &sheets.ValueRange{Range: writeRange, Values: [][]interface{}{row.Values}}
+
+			if index, ok := written[writeRange]; ok {
+				data[index] = valueRange
+			} else {
+				written[writeRange] = len(data)
+				data = append(data, valueRange)
+			}
+		}
+	}
+
+	return func() error {
+		_, err := Srv.Spreadsheets.Values.BatchUpdate(SpreadsheetId, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "RAW",
+			Data:             data,
+		}).Do()
+		return err
+	}
+}
+
+// Builds one append out of a run of appends to the same table
+func appendCall(run []sheetsOp) func() error {
+	var vr sheets.ValueRange
+	for _, op := range run {
+		vr.Values = append(vr.Values, op.values...)
+	}
+
+	writeRange := fmt.Sprintf("%v!B%v", run[0].tab, run[0].from)
+
+	return func() error {
+		_, err := Srv.Spreadsheets.Values.Append(SpreadsheetId, writeRange, &vr).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do()
+		return err
+	}
+}
+
+// Builds one batch clear out of a run of clears
+func clearCall(run []sheetsOp) func() error {
+	var ranges []string
+	for _, op := range run {
+		for _, tab := range op.tabs {
+			ranges = append(ranges, tab+"!B2:Z")
+		}
+	}
+
+	return func() error {
+		_, err := Srv.Spreadsheets.Values.BatchClear(SpreadsheetId, &sheets.BatchClearValuesRequest{Ranges: ranges}).Do()
+		return err
+	}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+// Sets up the fake sheets backend in memory for one test
+func setupTestSheets(t *testing.T) {
+	t.Helper()
+
+	previousBackend, previousSrv, previousID := CachedConfigs.SheetsBackend, Srv, SpreadsheetId
+	t.Cleanup(func() {
+		CachedConfigs.SheetsBackend, Srv, SpreadsheetId = previousBackend, previousSrv, previousID
+	})
+	CachedConfigs.SheetsBackend = SheetsBackendFake
+	SetupFakeSheetsAPI("", "")
+}
+
+func TestCoalesceSheetsOpsKeepsOrder(t *testing.T) {
+	write := func(row int) sheetsOp {
+		return sheetsOp{kind: sheetsWrite, tab: TabRawData, rows: []SinkRow{{Row: row, Values: []interface{}{row}}}}
+	}
+	appendTo := func(tab string, from int) sheetsOp {
+		return sheetsOp{kind: sheetsAppend, tab: tab, from: from, values: [][]interface{}{{tab}}}
+	}
+	clearRawData := sheetsOp{kind: sheetsClear, tabs: []string{TabRawData}}
+
+	ops := []sheetsOp{
+		write(2), write(3), write(2),
+		appendTo(TabRawData, 2), appendTo(TabRawData, 2), appendTo(TabPitScouting, 2),
+		clearRawData, clearRawData,
+		write(4),
+	}
+
+	calls := coalesceSheetsOps(ops)
+
+	var sizes []int
+	for _, call := range calls {
+		sizes = append(sizes, len(call.ops))
+	}
+	if want := []int{3, 2, 1, 2, 1}; !slices.Equal(sizes, want) {
+		t.Errorf("ops per call = %v, want %v", sizes, want)
+	}
+
+	if len(coalesceSheetsOps(nil)) != 0 {
+		t.Error("calls made with nothing buffered")
+	}
+}
+
+func TestCoalescedWritesKeepLastWrite(t *testing.T) {
+	setupTestSheets(t)
+
+	calls := coalesceSheetsOps([]sheetsOp{
+		{kind: sheetsWrite, tab: TabRawData, rows: []SinkRow{{Row: 2, Values: []interface{}{"first"}}, {Row: 3, Values: []interface{}{"other"}}}},
+		{kind: sheetsWrite, tab: TabRawData, rows: []SinkRow{{Row: 2, Values: []interface{}{"second"}}}},
+		{kind: sheetsAppend, tab: TabPitScouting, from: 2, values: [][]interface{}{{"a"}}},
+		{kind: sheetsAppend, tab: TabPitScouting, from: 2, values: [][]interface{}{{"b"}}},
+	})
+	if len(calls) != 2 {
+		t.Fatalf("%v calls, want 2", len(calls))
+	}
+	for _, call := range calls {
+		if err := call.do(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	response, err := Srv.Spreadsheets.Values.BatchGet(SpreadsheetId).Ranges("RawData!B2:B3", "PitScouting!B2:B3").Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cellTexts(flatten(response.ValueRanges[0].Values)); !slices.Equal(got, []string{"second", "other"}) {
+		t.Errorf("RawData reads %v, want the last write to row 2", got)
+	}
+	if got := cellTexts(flatten(response.ValueRanges[1].Values)); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("PitScouting reads %v, want both appends in order", got)
+	}
+}
+
+// Turns a column of cells into one list
+func flatten(rows [][]interface{}) []interface{} {
+	var cells []interface{}
+	for _, row := range rows {
+		cells = append(cells, row...)
+	}
+	return cells
+}
+
+func TestSheetsWritesDontWaitForTheSheet(t *testing.T) {
+	setupTestEnvironment(t)
+	setupTestSheets(t)
+	sink := newSheetsSink()
+	t.Cleanup(sink.Stop)
+	setupTestSink(t, sink)
+
+	writeTestEntry(t, GetCurrentEvent()+"_5_red1_1.json", "a", false)
+	if err := WriteSinkRows("Nope", []SinkRow{{Row: 2, Values: []interface{}{"x"}}}); err != nil {
+		t.Fatalf("queueing a write failed: %v", err)
+	}
+	if sink.Pending() != 1 {
+		t.Errorf("%v writes pending, want the queued one", sink.Pending())
+	}
+
+	if err := sink.Flush(); err == nil {
+		t.Error("flush of a write to a missing tab worked")
+	}
+	if status := GetSinkStatuses()[0]; !status.Stale || status.Failures != 1 {
+		t.Errorf("status after a failed flush = %+v, want it stale", status)
+	}
+
+	report, err := ReconcileSheet(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Mismatches) == 0 || !report.Rewritten {
+		t.Errorf("reconciliation didn't rewrite the entry missing from the sheet: %+v", report)
+	}
+	if GetSinkStatuses()[0].Stale {
+		t.Error("sheet still stale after reconciliation caught it up")
+	}
+
+	if report, err := ReconcileSheet(false); err != nil || len(report.Mismatches) != 0 {
+		t.Errorf("sheet doesn't match after catching up: %+v, %v", report.Mismatches, err)
+	}
+}
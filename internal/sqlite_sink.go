@@ -0,0 +1,92 @@
+package internal
+
+// An output sink that keeps every row in a local sqlite table, for events without Google access
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Writes rows to the rows table of its own database, keyed by tab and row number. Cells are stored as a json array.
+type sqliteSink struct {
+	db    *sql.DB
+	mutex sync.Mutex // Keeps two appends from picking the same row
+}
+
+// Opens a sqlite sink at the passed in database file, creating its table if it doesn't exist
+func newSqliteSink(path string) (*sqliteSink, error) {
+	db, openErr := sql.Open(CachedConfigs.SqliteDriver, path+"?_busy_timeout=5000")
+	if openErr != nil {
+		return nil, openErr
+	}
+
+	_, execErr := db.Exec(`create table if not exists rows(
+		tab text not null,
+		row int not null,
+		cells text not null,
+		updated int not null,
+		primary key(tab, row)
+	)`)
+	if execErr != nil {
+		db.Close()
+		return nil, execErr
+	}
+
+	return &sqliteSink{db: db}, nil
+}
+
+// Writes over the passed in rows in one transaction
+func (sink *sqliteSink) WriteRows(tab string, rows []SinkRow) error {
+	tx, beginErr := sink.db.Begin()
+	if beginErr != nil {
+		return beginErr
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		cells, marshalErr := json.Marshal(row.Values)
+		if marshalErr != nil {
+			return marshalErr
+		}
+
+		_, execErr := tx.Exec(
+			"insert into rows values(?, ?, ?, ?) on conflict(tab, row) do update set cells = excluded.cells, updated = excluded.updated",
+			tab, row.Row, string(cells), time.Now().UnixMilli(),
+		)
+		if execErr != nil {
+			return execErr
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Adds rows after the highest one in the tab, ignoring where the data starts
+func (sink *sqliteSink) AppendRows(tab string, from int, values [][]interface{}) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	var last int
+	if scanErr := sink.db.QueryRow("select coalesce(max(row), 1) from rows where tab = ?", tab).Scan(&last); scanErr != nil {
+		return scanErr
+	}
+
+	var rows []SinkRow
+	for i, row := range values {
+		rows = append(rows, SinkRow{Row: last + 1 + i, Values: row})
+	}
+
+	return sink.WriteRows(tab, rows)
+}
+
+// Deletes every row but the header of the passed in tabs
+func (sink *sqliteSink) ClearTabs(tabs []string) error {
+	for _, tab := range tabs {
+		if _, execErr := sink.db.Exec("delete from rows where tab = ? and row > 1", tab); execErr != nil {
+			return execErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package internal
+
+// Where submissions are in the pipeline, for the scouters who sent them and for admins keeping an eye on ingestion
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Where a submission is, as told to the scouter who sent it
+type ReceiptState string
+
+// Receipt state enum
+const (
+	ReceiptQueued    ReceiptState = "queued"    // Waiting in In to be processed
+	ReceiptRetrying  ReceiptState = "retrying"  // In In, but processing has failed at least once and will be tried again
+	ReceiptWritten   ReceiptState = "written"   // Written to the sheet
+	ReceiptErrored   ReceiptState = "errored"   // Given up on, an admin needs to look at it
+	ReceiptDiscarded ReceiptState = "discarded" // Superseded by a rescout or thrown out by an admin
+	ReceiptUnknown   ReceiptState = "unknown"   // Not a submission of the user asking
+)
+
+// The lifecycle state of one submission
+type SubmissionReceipt struct {
+	File       string       `json:"File"`                 // The name returned when it was submitted, without .json
+	State      ReceiptState `json:"State"`                // Where it is
+	Match      uint         `json:"Match,omitempty"`      // The match it was for, 0 for pit scouting
+	Team       int          `json:"Team,omitempty"`       // The team scouted
+	Received   int64        `json:"Received,omitempty"`   // When it was submitted, in unix milliseconds
+	Attempts   int          `json:"Attempts,omitempty"`   // How many times processing has failed
+	RetryAfter *time.Time   `json:"RetryAfter,omitempty"` // When processing will next be tried, if it is retrying
+	Error      string       `json:"Error,omitempty"`      // The last error processing it, if it is retrying or errored
+}
+
+// The directories a submission of the current event can be in, and what they mean to the scouter
+var receiptDirectories = []struct {
+	directory string
+	state     ReceiptState
+}{
+	{PipelineIn, ReceiptQueued},
+	{PipelineWritten, ReceiptWritten},
+	{PipelinePitWritten, ReceiptWritten},
+	{PipelinePrescouted, ReceiptWritten},
+	{PipelineErrored, ReceiptErrored},
+	{PipelineDiscarded, ReceiptDiscarded},
+}
+
+// Builds the receipt of a file found in the pipeline
+func receiptOf(entry PipelineEntry, state ReceiptState) SubmissionReceipt {
+	receipt := SubmissionReceipt{
+		File:     strings.TrimSuffix(entry.File, ".json"),
+		State:    state,
+		Match:    entry.Match,
+		Team:     entry.Team,
+		Received: entry.Received,
+	}
+
+	switch state {
+	case ReceiptQueued:
+		if retry, failed := getRetryState(entry.File); failed {
+			receipt.State = ReceiptRetrying
+			receipt.Attempts = retry.attempts
+			receipt.RetryAfter = &retry.retryAfter
+		}
+	case ReceiptErrored:
+		if record, found := GetDeadLetterRecord(entry.File); found {
+			receipt.Attempts = record.Attempts
+			receipt.Error = record.Error
+		}
+	}
+
+	return receipt
+}
+
+// Gets the receipt of one submission by the name it was returned as. Submissions of other scouters are unknown, unless asked by an admin.
+func GetSubmissionReceipt(file string, scouter string, isAdmin bool) SubmissionReceipt {
+	fileName := strings.TrimSuffix(file, ".json") + ".json"
+
+	for _, dir := range receiptDirectories {
+		if _, ok := readPipelineFile(dir.directory, fileName); !ok {
+			continue
+		}
+
+		entry := describePipelineFile(dir.directory, fileName)
+		if !isAdmin && !strings.EqualFold(entry.Scouter, scouter) {
+			break
+		}
+
+		return receiptOf(entry, dir.state)
+	}
+
+	return SubmissionReceipt{File: strings.TrimSuffix(file, ".json"), State: ReceiptUnknown}
+}
+
+// Gets the receipts of every submission a scouter has made at the current event, newest first
+func GetScouterReceipts(scouter string) []SubmissionReceipt {
+	receipts := []SubmissionReceipt{}
+
+	for _, dir := range receiptDirectories {
+		filter := PipelineFilter{Directory: dir.directory, Event: GetCurrentEvent(), Scouter: scouter}
+		for _, entry := range ListPipelineEntries(filter) {
+			receipts = append(receipts, receiptOf(entry, dir.state))
+		}
+	}
+
+	sort.Slice(receipts, func(i, j int) bool {
+		return receipts[i].Received > receipts[j].Received
+	})
+
+	return receipts
+}
+
+// Successfully processed files, kept for reporting throughput
+var ingestStats = struct {
+	mutex     sync.Mutex
+	lastFile  string      // The last file processed
+	lastTime  time.Time   // When it was processed
+	recent    []time.Time // When every file in the last hour was processed, oldest first
+	processed int         // How many files have been processed since startup
+}{}
+
+// Records that a file was successfully processed
+func recordProcessed(fileName string) {
+	now := time.Now()
+
+	ingestStats.mutex.Lock()
+	defer ingestStats.mutex.Unlock()
+
+	ingestStats.lastFile = fileName
+	ingestStats.lastTime = now
+	ingestStats.processed++
+	ingestStats.recent = append(ingestStats.recent, now)
+
+	cutoff := now.Add(-time.Hour)
+	trim := 0
+	for trim < len(ingestStats.recent) && ingestStats.recent[trim].Before(cutoff) {
+		trim++
+	}
+	ingestStats.recent = ingestStats.recent[trim:]
+}
+
+// A summary of how ingestion is doing
+type IngestStatus struct {
+	Workers             int        `json:"Workers"`             // How many ingestion workers are running
+	QueueDepth          int        `json:"QueueDepth"`          // How many files are waiting in In
+	InProgress          int        `json:"InProgress"`          // How many files are queued in memory or being processed right now
+	Retrying            int        `json:"Retrying"`            // How many files in In are waiting out a backoff
+	Errored             int        `json:"Errored"`             // How many files are in Errored
+	LastProcessed       string     `json:"LastProcessed"`       // The last file processed since startup
+	LastProcessedAt     *time.Time `json:"LastProcessedAt"`     // When it was processed
+	ProcessedLastMinute int        `json:"ProcessedLastMinute"` // Files processed in the last minute
+	ProcessedLastHour   int        `json:"ProcessedLastHour"`   // Files processed in the last hour
+	ProcessedTotal      int        `json:"ProcessedTotal"`      // Files processed since startup
+}
+
+// Gets a summary of how ingestion is doing
+func GetIngestStatus() IngestStatus {
+	status := IngestStatus{
+		Workers:  CachedConfigs.IngestWorkers,
+		Retrying: retryingCount(),
+		Errored:  len(pipelineFiles(PipelineErrored)),
+	}
+
+	if inJson, readErr := os.ReadDir(JsonInDirectory); readErr == nil {
+		for _, file := range inJson {
+			if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+				status.QueueDepth++
+			}
+		}
+	} else {
+		LogErrorf(readErr, "Problem reading %v", JsonInDirectory)
+	}
+
+	ingestion.mutex.Lock()
+	status.InProgress = len(ingestion.pending)
+	ingestion.mutex.Unlock()
+
+	ingestStats.mutex.Lock()
+	defer ingestStats.mutex.Unlock()
+
+	status.ProcessedTotal = ingestStats.processed
+	status.LastProcessed = ingestStats.lastFile
+	if !ingestStats.lastTime.IsZero() {
+		lastTime := ingestStats.lastTime
+		status.LastProcessedAt = &lastTime
+	}
+
+	hourAgo, minuteAgo := time.Now().Add(-time.Hour), time.Now().Add(-time.Minute)
+	for _, processed := range ingestStats.recent {
+		if processed.After(hourAgo) {
+			status.ProcessedLastHour++
+		}
+		if processed.After(minuteAgo) {
+			status.ProcessedLastMinute++
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,173 @@
+package internal
+
+// Naming, deduplication and queueing of submitted scouting data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What happened to a submitted entry
+type SubmissionStatus string
+
+// Submission status enum
+const (
+	SubmissionAccepted  SubmissionStatus = "accepted"  // Newly stored and queued for processing
+	SubmissionDuplicate SubmissionStatus = "duplicate" // Already known, nothing was stored
+	SubmissionRejected  SubmissionStatus = "rejected"  // Not stored, see the reason
+)
+
+// The result of submitting one entry
+type SubmissionResult struct {
+	Status   SubmissionStatus `json:"Status"`             // What happened to the entry
+	File     string           `json:"File,omitempty"`     // The name the entry is (or already was) stored under without .json, or the mangled record it was kept as
+	Reason   string           `json:"Reason,omitempty"`   // Why the entry was rejected
+	Errors   []FieldError     `json:"Errors,omitempty"`   // The fields that failed validation, if that's why it was rejected
+	Warnings []FieldError     `json:"Warnings,omitempty"` // How the entry doesn't match the schedule. It's kept anyway.
+
+	invalid bool // If it was rejected for something wrong with the entry, rather than a problem on the server
+}
+
+// Names, stores and queues a match entry of the current season, the same way for every endpoint that accepts them.
+// The scouter should already be set from the authenticated user, as the client isn't trusted with it.
+func SubmitMatchEntry(entry MatchEntry) SubmissionResult {
+	info := entry.Info()
+
+	submissionID, idValid := canonicalSubmissionID(info.SubmissionID)
+	if !idValid {
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Submission ID is not a valid UUID", invalid: true}
+	}
+
+	fieldErrs, warnings := ValidateMatchEntry(CurrentSeason(), entry)
+	if len(fieldErrs) > 0 {
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Entry failed validation", Errors: fieldErrs, Warnings: warnings, invalid: true}
+	}
+
+	entryBytes, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem encoding %v", entry)
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to encode entry"}
+	}
+
+	//EVENT_MATCH_{COLOR}{DSNUM}
+	slot := fmt.Sprintf(
+		"%s_%v_%s",
+		GetCurrentEvent(),
+		info.Match.Number,
+		GetDSString(info.DriverStation.IsBlue, uint(info.DriverStation.Number)),
+	)
+
+	//EVENT_MATCH_{COLOR}{DSNUM}_SystemTimeMS
+	//TODO: file naming stuff here -Leon
+	fileName, tempFile, reserveErr := reserveFileName(JsonInDirectory, slot+"_%v")
+	if reserveErr != nil {
+		LogErrorf(reserveErr, "Problem naming an entry for %v", slot)
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}
+	}
+
+	if submissionID != "" { // Clients that send an ID get exact deduplication, no matter where the first copy ended up
+		existing, isNew, claimErr := ClaimSubmissionID(submissionID, fileName+".json", info.Scouter)
+		if claimErr != nil {
+			LogErrorf(claimErr, "Problem claiming submission id %v", submissionID)
+			releaseFileName(tempFile)
+			return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}
+		}
+		if !isNew {
+			releaseFileName(tempFile)
+			return SubmissionResult{Status: SubmissionDuplicate, File: strings.TrimSuffix(existing, ".json")}
+		}
+	}
+
+	if !writeSubmission(fileName+".json", tempFile, entryBytes) {
+		if submissionID != "" {
+			ReleaseSubmissionID(submissionID)
+		}
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}
+	}
+	StoreEntryWarnings(fileName+".json", warnings)
+
+	return SubmissionResult{Status: SubmissionAccepted, File: fileName, Warnings: warnings}
+}
+
+// Names, stores and queues a pit scouting entry, the same way for every endpoint that accepts them.
+func SubmitPitEntry(pit PitEntry) SubmissionResult {
+	pitBytes, marshalErr := json.Marshal(pit)
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem encoding %v", pit)
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to encode entry"}
+	}
+
+	//EVENT_TEAM_SystemTimeMS, so every visit to a team's pit is kept
+	fileName, tempFile, reserveErr := reserveFileName(JsonInDirectory, fmt.Sprintf("%s_%v", GetCurrentEvent(), pit.Team())+"_%v")
+	if reserveErr != nil {
+		LogErrorf(reserveErr, "Problem naming a pit entry for %v", pit.Team())
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}
+	}
+
+	if !writeSubmission(fileName+".json", tempFile, pitBytes) {
+		return SubmissionResult{Status: SubmissionRejected, Reason: "Unable to store entry"}
+	}
+
+	return SubmissionResult{Status: SubmissionAccepted, File: fileName}
+}
+
+// Gets the canonical form of a client submission ID, so the same UUID in different cases is still recognized.
+// An empty ID is valid, and stays empty.
+func canonicalSubmissionID(id string) (string, bool) {
+	if id == "" {
+		return "", true
+	}
+
+	parsedID, parseErr := uuid.Parse(id)
+	if parseErr != nil {
+		return "", false
+	}
+	return parsedID.String(), true
+}
+
+// Claims a name in a directory, filling the passed in format (which takes one %v) with the current system time in milliseconds
+// and bumping the time forward until the name is free. The name is held by exclusively creating its temporary file,
+// so two submissions in the same millisecond can't both get it. The temporary file is returned open, to be written and
+// moved into place by writeSubmission or given up with releaseFileName.
+func reserveFileName(directory string, format string) (string, *os.File, error) {
+	millis := time.Now().UnixMilli()
+	for {
+		fileName := fmt.Sprintf(format, millis)
+		millis++
+
+		finalPath := filepath.Join(directory, fileName+".json")
+		tempFile, createErr := createTempFile(finalPath)
+		if os.IsExist(createErr) {
+			continue
+		} else if createErr != nil {
+			return "", nil, createErr
+		}
+
+		// Checked after the temporary file is held, so a file moved into place in the meantime is still seen
+		if _, statErr := os.Stat(finalPath); !os.IsNotExist(statErr) {
+			releaseFileName(tempFile)
+			continue
+		}
+
+		return fileName, tempFile, nil
+	}
+}
+
+// Exclusively creates the temporary file something is written to before being moved to the passed in path
+func createTempFile(finalPath string) (*os.File, error) {
+	return os.OpenFile(finalPath+".tmp", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0777)
+}
+
+// Gives up a name claimed with reserveFileName without writing anything to it
+func releaseFileName(tempFile *os.File) {
+	tempFile.Close()
+	if removeErr := os.Remove(tempFile.Name()); removeErr != nil {
+		LogErrorf(removeErr, "Problem removing %v", tempFile.Name())
+	}
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestReserveFileNameIsUnique(t *testing.T) {
+	setupTestEnvironment(t)
+
+	// Taken before anyone asks, so the first names tried are already in use
+	taken, tempFile, err := reserveFileName(JsonInDirectory, "2026test_4_red1_%v")
+	if err != nil || !writeSubmission(taken+".json", tempFile, []byte("{}")) {
+		t.Fatal("couldn't write the first entry", err)
+	}
+
+	const submissions = 20
+	names := make(chan string, submissions)
+	var wg sync.WaitGroup
+	for range submissions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fileName, tempFile, err := reserveFileName(JsonInDirectory, "2026test_4_red1_%v")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !writeSubmission(fileName+".json", tempFile, []byte("{}")) {
+				t.Errorf("couldn't write %v", fileName)
+			}
+			names <- fileName
+		}()
+	}
+	wg.Wait()
+	close(names)
+
+	seen := map[string]bool{taken: true}
+	for name := range names {
+		if seen[name] {
+			t.Errorf("%v was given out twice", name)
+		}
+		seen[name] = true
+	}
+
+	files, _ := os.ReadDir(JsonInDirectory)
+	if len(files) != submissions+1 {
+		t.Errorf("%v files in In, want %v without any temporary files", len(files), submissions+1)
+	}
+}
@@ -54,6 +54,9 @@ func InitUserDB() {
 		{columnName: "accolades", valueType: "TEXT", defaultValue: sql.NullString{String: "'[]'", Valid: true}},
 		{columnName: "color", valueType: "INT"},
 		{columnName: "theme", valueType: "TEXT", defaultValue: sql.NullString{String: "'Light'", Valid: true}},
+		{columnName: "reliability", valueType: "REAL", defaultValue: sql.NullString{String: kReliabilityPriorString, Valid: true}},
+		{columnName: "reliabilitychecks", valueType: "INT", defaultValue: sql.NullString{String: "0", Valid: true}},
+		{columnName: "reliabilitymisses", valueType: "INT", defaultValue: sql.NullString{String: "0", Valid: true}},
 	}
 
 	if dbMissing {
@@ -391,7 +394,7 @@ func NewUser(username string, uuid string) {
 	}
 
 	//The only reason most of these columns don't have default values is that sqlite doesn't let you alter column default values and I don't feel like deleting and remaking every column
-	_, err := userDB.Exec("insert into users values(?,?,?,?,?,?,?, 0, 0, ?, 0, ?)", uuid, username, username, nil, string(badgeBytes), 0, DefaultPfpPath, "[]", "light")
+	_, err := userDB.Exec("insert into users values(?,?,?,?,?,?,?, 0, 0, ?, 0, ?, ?, 0, 0)", uuid, username, username, nil, string(badgeBytes), 0, DefaultPfpPath, "[]", "light", kReliabilityPrior)
 
 	if err != nil {
 		LogErrorf(err, "Problem creating new user with args: %v, %v, %v, %v, %v, %v, %v", uuid, username, username, "nil", badgeBytes, 0, DefaultPfpPath)
@@ -9,7 +9,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"slices"
 	"strconv"
 	"strings"
 )
@@ -126,7 +125,7 @@ func GetCycleAccuracy(cycles []Cycle) any {
 }
 
 // Gets the accuracy of a robot during an autonomous period, returning N/A if 0 attempts were made
-func GetAutoAccuracy(auto AutoData) any { // TODO: FIX FOR NEW
+func GetAutoAccuracy(auto AutoData) any {
 	attempts := auto.Scores + auto.Misses
 
 	if attempts == 0 {
@@ -167,29 +166,21 @@ func CompileNotes(team TeamData) string {
 
 // Compiles Losing track, DCs, and notes into one string of notes.
 // Used for multi-scouting only
-func CompileNotes2(match MultiMatch, teams []TeamData) string {
+func CompileNotes2(match MultiMatch) string {
 	var finalNote string = ""
-	var lostTrack bool = false
-	var DC bool = false
 
-	for _, entry := range teams {
-		if entry.Issues.LoseTrack {
-			lostTrack = true
-		}
-
-		if entry.Issues.Disconnect {
-			DC = true
-		}
-	}
-
-	if lostTrack {
+	if match.Issues.LoseTrack {
 		finalNote += "LOST TRACK; "
 	}
 
-	if DC {
+	if match.Issues.Disconnect {
 		finalNote += "DISCONNECTED; "
 	}
 
+	if match.Issues.EverBeached {
+		finalNote += "WAS BEACHED; "
+	}
+
 	finalNote += strings.Join(match.Notes, "; ")
 	return finalNote
 }
@@ -346,8 +337,8 @@ func GetDSOffset(ds string) int {
 	return 0
 }
 
-// Gets the row an entry will write to from its Teamdata object
-func GetRow(team TeamData) int { //TODO: Update so it doesn't rely on match # -Leon
+// Gets the row an entry will write to from the parts every season shares
+func GetRow(team EntryInfo) int { //TODO: Update so it doesn't rely on match # -Leon
 	startRow := 2 + (team.Match.Number-1)*6
 	dsString := GetDSString(team.DriverStation.IsBlue, uint(team.DriverStation.Number))
 	dsOffset := GetDSOffset(dsString)
@@ -357,11 +348,6 @@ func GetRow(team TeamData) int { //TODO: Update so it doesn't rely on match # -L
 	return int(startRow)
 }
 
-// Gets the row a pit scouting data should write to
-func GetPitRow(team int) int {
-	return slices.Index(Teams, team) + 1
-}
-
 // Getter for the current event key
 func GetCurrentEvent() string {
 	return CachedConfigs.EventKey
@@ -382,31 +368,17 @@ func CompareSplits(first []string, second []string) bool {
 	return true
 }
 
-// Gets all files matching the passed in pattern; Used to find all files of one entry when multi-scouting
-func GetAllMatching(checkAgainst string) []string {
+// Gets the files in Written scouting the same robot in the same match as the passed in file. Used to find all files of one entry when multi-scouting.
+// Replays are kept apart from the original match.
+func GetAllMatching(fileName string, info EntryInfo) []string {
 	var results []string
-	splitAgainst := strings.Split(checkAgainst, "_")
-
-	writtenJson, err := os.ReadDir(JsonWrittenDirectory)
-
-	if err != nil {
-		LogErrorf(err, "Error reading directory %v", JsonWrittenDirectory)
-		return results
-	}
-
-	if len(writtenJson) > 0 {
-		for _, jsonFile := range writtenJson {
-			splitFile := strings.Split(jsonFile.Name(), "_")
 
-			if len(splitFile) < 3 || len(splitAgainst) < 3 {
-				continue
-			}
-
-			if CompareSplits(splitAgainst[:3], splitFile[:3]) {
-				results = append(results, jsonFile.Name())
-			}
+	for _, file := range GetSlotFiles(eventFromFileName(fileName), info.Match, info.DriverStation.IsBlue, info.DriverStation.Number, EntryWritten) {
+		if file != fileName {
+			results = append(results, file)
 		}
 	}
+
 	return results
 }
 
@@ -431,6 +403,32 @@ func GetNumMatches() int {
 	return len(result)
 }
 
+// The teams on each alliance of every match, keyed by match number then "Blue" or "Red", as written by getSchedule.py
+type EventSchedule map[uint]map[string][]int
+
+// Reads the current event's schedule.json, returning false if there isn't a usable one (custom events without a schedule, TBA outages)
+func GetSchedule() (EventSchedule, bool) {
+	var schedule EventSchedule
+
+	schedPath := filepath.Join(CachedConfigs.RuntimeDirectory, "schedule.json")
+	scheduleBytes, readErr := readFileIfExists(schedPath)
+	if readErr != nil {
+		LogErrorf(readErr, "Error reading %v", schedPath)
+		return schedule, false
+	}
+
+	if scheduleBytes == nil {
+		return schedule, false
+	}
+
+	if decodeErr := json.Unmarshal(scheduleBytes, &schedule); decodeErr != nil {
+		LogErrorf(decodeErr, "Error Decoding %v", schedPath)
+		return schedule, false
+	}
+
+	return schedule, len(schedule) > 0
+}
+
 // Moves a file from an original path to a new one, returning wether or not it was successful
 func MoveFile(originalPath string, newPath string) bool {
 	oldLoc, openErr := os.Open(originalPath)
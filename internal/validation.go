@@ -0,0 +1,133 @@
+package internal
+
+// Field-level validation of submitted scouting data
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The most of any one counter a robot could reasonably rack up in a match
+const kMaxCounter = 500
+
+// The length of a match in seconds, which no timer can go past
+const kMatchLengthSeconds = 160
+
+// A problem with one field of a submission
+type FieldError struct {
+	Field   string `json:"Field"`   // The json path of the field, e.g. auto.accuracy.hpAccuracy
+	Message string `json:"Message"` // What is wrong with it
+}
+
+// Checks a match entry against the current event, then hands it to its season to check the game-specific fields.
+// Returns every field that is wrong with it, and every way it doesn't match the event's schedule.
+// A team that isn't attending or a match that isn't in the schedule is an error. A team in a different driverstation than
+// the schedule says is only a warning, as substitutions happen and playoffs overwrite quals in the schedule (it's keyed by
+// match number alone), so those entries are still kept.
+func ValidateMatchEntry(season *GameSeason, entry MatchEntry) (errs []FieldError, warnings []FieldError) {
+	addError := func(field string, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(field string, format string, args ...any) {
+		warnings = append(warnings, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	info := entry.Info()
+
+	teamValid := false
+	if info.TeamNumber < 1 {
+		addError("team", "Team number must be at least 1")
+	} else if len(Teams) > 0 && !slices.Contains(Teams, int(info.TeamNumber)) {
+		addError("team", "Team %v is not attending %v", info.TeamNumber, GetCurrentEvent())
+	} else {
+		teamValid = true
+	}
+
+	stationValid := info.DriverStation.Number >= 1 && info.DriverStation.Number <= 3
+	if !stationValid {
+		addError("driverStation.number", "Driver station must be 1-3, not %v", info.DriverStation.Number)
+	}
+
+	if info.Match.Number < 1 {
+		addError("match.number", "Match number must be at least 1")
+	} else if schedule, ok := GetSchedule(); ok && !info.Prescouting {
+		alliances, inSchedule := schedule[info.Match.Number]
+		if !inSchedule {
+			addError("match.number", "Match %v is not in the schedule for %v", info.Match.Number, GetCurrentEvent())
+		} else if stationValid && teamValid {
+			color := "Red"
+			if info.DriverStation.IsBlue {
+				color = "Blue"
+			}
+
+			alliance := alliances[color]
+			if len(alliance) >= info.DriverStation.Number && alliance[info.DriverStation.Number-1] != int(info.TeamNumber) {
+				addWarning("team", "%v %v in match %v is team %v, not %v",
+					color, info.DriverStation.Number, info.Match.Number, alliance[info.DriverStation.Number-1], info.TeamNumber)
+			}
+		}
+	}
+
+	if season.ValidateMatch != nil {
+		errs = append(errs, season.ValidateMatch(entry)...)
+	}
+
+	return errs, warnings
+}
+
+// Stores the warnings of an entry in matches.db, so admins can see them later
+func StoreEntryWarnings(fileName string, warnings []FieldError) {
+	for _, warning := range warnings {
+		_, err := matchDB.Exec("insert into entry_warnings(file, field, message) values(?, ?, ?)", fileName, warning.Field, warning.Message)
+		if err != nil {
+			LogErrorf(err, "Problem storing warning %v of %v", warning, fileName)
+		}
+	}
+}
+
+// Gets the warnings an entry was stored with
+func GetEntryWarnings(fileName string) []FieldError {
+	var warnings []FieldError
+
+	rows, err := matchDB.Query("select field, message from entry_warnings where file = ? order by id", fileName)
+	if err != nil {
+		LogErrorf(err, "Problem looking up the warnings of %v", fileName)
+		return warnings
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var warning FieldError
+		if scanErr := rows.Scan(&warning.Field, &warning.Message); scanErr != nil {
+			LogError(scanErr, "Problem scanning response to warning lookup")
+			continue
+		}
+		warnings = append(warnings, warning)
+	}
+
+	return warnings
+}
+
+// Adds an error if a counter isn't between 0 and kMaxCounter
+func checkCounter(errs []FieldError, field string, value int) []FieldError {
+	if value < 0 || value > kMaxCounter {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("Must be between 0 and %v, not %v", kMaxCounter, value)})
+	}
+	return errs
+}
+
+// Adds an error if a percentage isn't between 0 and 100
+func checkPercentage(errs []FieldError, field string, value int) []FieldError {
+	if value < 0 || value > 100 {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("Must be a percentage between 0 and 100, not %v", value)})
+	}
+	return errs
+}
+
+// Adds an error if a timer isn't between 0 and the length of a match
+func checkTimer(errs []FieldError, field string, value float64) []FieldError {
+	if value < 0 || value > kMatchLengthSeconds {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("Must be between 0 and %v seconds, not %v", kMatchLengthSeconds, value)})
+	}
+	return errs
+}
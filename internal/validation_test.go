@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Gives the test event a two match schedule and its team list
+func setupTestSchedule(t *testing.T) {
+	t.Helper()
+
+	schedule := EventSchedule{
+		1: {"Red": {1816, 254, 118}, "Blue": {971, 1678, 2056}},
+		2: {"Red": {971, 1678, 2056}, "Blue": {1816, 254, 118}},
+	}
+	scheduleBytes, _ := json.Marshal(schedule)
+	if err := os.WriteFile(filepath.Join(CachedConfigs.RuntimeDirectory, "schedule.json"), scheduleBytes, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := Teams
+	t.Cleanup(func() { Teams = previous })
+	Teams = []int{1816, 254, 118, 971, 1678, 2056}
+}
+
+// Builds a valid entry for red 1 in match 1, changed by the passed in function
+func testTeamData(change func(team *TeamData)) *TeamData {
+	team := &TeamData{TeamNumber: 1816}
+	team.Match.Number = 1
+	team.DriverStation.Number = 1
+	if change != nil {
+		change(team)
+	}
+	return team
+}
+
+func TestValidateMatchEntry(t *testing.T) {
+	setupTestEnvironment(t)
+	setupTestSchedule(t)
+
+	cases := map[string]struct {
+		team     *TeamData
+		errors   []string
+		warnings []string
+	}{
+		"valid":                 {testTeamData(nil), nil, nil},
+		"team 0":                {testTeamData(func(team *TeamData) { team.TeamNumber = 0 }), []string{"team"}, nil},
+		"team not attending":    {testTeamData(func(team *TeamData) { team.TeamNumber = 9999 }), []string{"team"}, nil},
+		"match not in schedule": {testTeamData(func(team *TeamData) { team.Match.Number = 9999 }), []string{"match.number"}, nil},
+		"match 0":               {testTeamData(func(team *TeamData) { team.Match.Number = 0 }), []string{"match.number"}, nil},
+		"other alliance slot":   {testTeamData(func(team *TeamData) { team.DriverStation.Number = 2 }), nil, []string{"team"}},
+		"driver station 7":      {testTeamData(func(team *TeamData) { team.DriverStation.Number = 7 }), []string{"driverStation.number"}, nil},
+		"negative counter":      {testTeamData(func(team *TeamData) { team.Auto.Scores = -1 }), []string{"auto.scores"}, nil},
+		"counter too high":      {testTeamData(func(team *TeamData) { team.Auto.Ejects = kMaxCounter + 1 }), []string{"auto.ejects"}, nil},
+		"percentage over 100":   {testTeamData(func(team *TeamData) { team.Auto.Accuracy.HPAccuracy = 101 }), []string{"auto.accuracy.hpAccuracy"}, nil},
+		"timer past the match":  {testTeamData(func(team *TeamData) { team.Endgame.ClimbTimer = kMatchLengthSeconds + 1 }), []string{"endgame.climbTimer"}, nil},
+	}
+
+	fields := func(fieldErrs []FieldError) []string {
+		var fields []string
+		for _, fieldErr := range fieldErrs {
+			fields = append(fields, fieldErr.Field)
+		}
+		return fields
+	}
+
+	for name, testCase := range cases {
+		errs, warnings := ValidateMatchEntry(CurrentSeason(), testCase.team)
+		if got := fields(errs); !slices.Equal(got, testCase.errors) {
+			t.Errorf("%v: errors on %v, want %v", name, got, testCase.errors)
+		}
+		if got := fields(warnings); !slices.Equal(got, testCase.warnings) {
+			t.Errorf("%v: warnings on %v, want %v", name, got, testCase.warnings)
+		}
+	}
+}
+
+func TestInvalidEntryAnswers422(t *testing.T) {
+	setupTestEnvironment(t)
+	setupTestSchedule(t)
+
+	team := testTeamData(func(team *TeamData) {
+		team.TeamNumber = 0
+		team.DriverStation.Number = 7
+	})
+
+	recorder := httptest.NewRecorder()
+	writeSubmissionResponse(recorder, SubmitMatchEntry(team))
+	if recorder.Code != 422 {
+		t.Fatalf("responded %v, want 422", recorder.Code)
+	}
+
+	var body []FieldError
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 2 || body[0].Field != "team" || body[1].Field != "driverStation.number" || body[0].Message == "" {
+		t.Errorf("unexpected errors %+v", body)
+	}
+
+	if files, _ := os.ReadDir(JsonInDirectory); len(files) != 0 {
+		t.Errorf("invalid entry was stored as %v", files[0].Name())
+	}
+}
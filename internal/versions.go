@@ -0,0 +1,354 @@
+package internal
+
+// Version history of match slots, so rescouts don't silently throw away what they replaced and can be rolled back
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Why a version of a match slot was made
+type VersionAction string
+
+// Version action enum
+const (
+	VersionOriginal VersionAction = "original" // What was there before the first rescout
+	VersionRescout  VersionAction = "rescout"  // A rescout replaced everything before it
+	VersionRollback VersionAction = "rollback" // An admin rolled back to an earlier version
+)
+
+// One entry that is part of a version
+type VersionEntry struct {
+	File    string     `json:"File"`    // The name of the file
+	Scouter string     `json:"Scouter"` // Who scouted it
+	State   EntryState `json:"State"`   // Where it currently is in the pipeline
+}
+
+// One version of what was written for a match slot
+type EntryVersion struct {
+	Version    int            `json:"Version"`    // The version number, counting up from 1
+	Entries    []VersionEntry `json:"Entries"`    // The entries that made up this version
+	Supersedes int            `json:"Supersedes"` // The version this one replaced, or 0 for the first one
+	Action     VersionAction  `json:"Action"`     // Why this version was made
+	Author     string         `json:"Author"`     // The scouter who rescouted, or the admin who rolled back
+	Created    time.Time      `json:"Created"`    // When this version was made
+}
+
+// Every version of one match slot
+type SlotHistory struct {
+	Event         string         `json:"Event"`         // The event key
+	Match         uint           `json:"Match"`         // The match number
+	Replay        bool           `json:"Replay"`        // If this is the replay of the match
+	DriverStation string         `json:"DriverStation"` // The driverstation (red1..blue3)
+	Current       int            `json:"Current"`       // The version on the sheet right now, or 0 if it was never rescouted
+	Versions      []EntryVersion `json:"Versions"`      // Every version, oldest first
+}
+
+// Turns a driverstation string (red1..blue3) back into its color and number, returning false if it isn't one
+func parseDSString(ds string) (bool, int, bool) {
+	for _, isBlue := range []bool{false, true} {
+		for number := 1; number <= 3; number++ {
+			if GetDSString(isBlue, uint(number)) == ds {
+				return isBlue, number, true
+			}
+		}
+	}
+	return false, 0, false
+}
+
+// Gets the files of every version of a match slot, oldest first
+func getSlotVersions(event string, match MatchInfo, ds string) ([]EntryVersion, error) {
+	versions := []EntryVersion{}
+
+	rows, err := matchDB.Query(
+		"select version, files, supersedes, action, author, created from entry_versions where event = ? and matchnum = ? and replay = ? and station = ? order by version",
+		event, match.Number, match.IsReplay, ds,
+	)
+	if err != nil {
+		return versions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version EntryVersion
+		var files string
+		var created int64
+		if scanErr := rows.Scan(&version.Version, &files, &version.Supersedes, &version.Action, &version.Author, &created); scanErr != nil {
+			return versions, scanErr
+		}
+
+		var fileNames []string
+		if unmarshalErr := json.Unmarshal([]byte(files), &fileNames); unmarshalErr != nil {
+			return versions, unmarshalErr
+		}
+		for _, file := range fileNames {
+			version.Entries = append(version.Entries, VersionEntry{File: file})
+		}
+
+		version.Created = time.UnixMilli(created)
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// Adds a new version of a match slot made up of the passed in files, returning its number
+func addSlotVersion(event string, match MatchInfo, ds string, files []string, supersedes int, action VersionAction, author string) (int, error) {
+	filesBytes, marshalErr := json.Marshal(files)
+	if marshalErr != nil {
+		return 0, marshalErr
+	}
+
+	var version int
+	err := matchDB.QueryRow(
+		`insert into entry_versions(event, matchnum, replay, station, version, files, supersedes, action, author, created)
+		select ?, ?, ?, ?, coalesce(max(version), 0) + 1, ?, ?, ?, ?, ? from entry_versions where event = ? and matchnum = ? and replay = ? and station = ?
+		returning version`,
+		event, match.Number, match.IsReplay, ds, string(filesBytes), supersedes, action, author, time.Now().UnixMilli(),
+		event, match.Number, match.IsReplay, ds,
+	).Scan(&version)
+
+	return version, err
+}
+
+// Gets the version history of a match slot, along with who scouted each entry and where it is now
+func GetSlotHistory(event string, match MatchInfo, ds string) (SlotHistory, error) {
+	history := SlotHistory{Event: event, Match: match.Number, Replay: match.IsReplay, DriverStation: ds}
+
+	versions, err := getSlotVersions(event, match, ds)
+	if err != nil {
+		return history, err
+	}
+
+	for _, version := range versions {
+		for i, entry := range version.Entries {
+			if stored, ok := GetStoredEntry(entry.File); ok {
+				version.Entries[i].Scouter = stored.Data.Info().Scouter
+				version.Entries[i].State = stored.State
+			}
+		}
+	}
+
+	history.Versions = versions
+	if len(versions) > 0 {
+		history.Current = versions[len(versions)-1].Version
+	}
+
+	return history, nil
+}
+
+// Gets the file names of a version's entries
+func versionFiles(version EntryVersion) []string {
+	var files []string
+	for _, entry := range version.Entries {
+		files = append(files, entry.File)
+	}
+	return files
+}
+
+// Replaces everything written for a match slot with a rescout, keeping track of what it replaced.
+// The first rescout of a slot also records what was there before it as the original version.
+// The versions are recorded before anything is discarded, so a rescout is never left without the history of what it replaced.
+func supersedeSlot(event string, info EntryInfo, fileName string, superseded []string) error {
+	ds := GetDSString(info.DriverStation.IsBlue, uint(info.DriverStation.Number))
+
+	versions, err := getSlotVersions(event, info.Match, ds)
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	if len(versions) == 0 {
+		current, err = addSlotVersion(event, info.Match, ds, superseded, 0, VersionOriginal, "")
+		if err != nil {
+			return err
+		}
+	} else {
+		current = versions[len(versions)-1].Version
+	}
+
+	if _, err = addSlotVersion(event, info.Match, ds, []string{fileName}, current, VersionRescout, info.Scouter); err != nil {
+		return err
+	}
+
+	for _, file := range superseded {
+		if MoveFile(filepath.Join(JsonWrittenDirectory, file), filepath.Join(JsonDiscardedDirectory, file)) {
+			SetEntryState(file, EntryDiscarded)
+		} else {
+			LogMessage("File " + filepath.Join(JsonWrittenDirectory, file) + " unable to be moved to Discarded")
+		}
+	}
+
+	return nil
+}
+
+// Adds a newly written entry to the current version of its match slot, if the slot has been rescouted before.
+// This keeps entries merged in after a rescout part of it, so rolling back and forth doesn't lose them.
+func addToCurrentVersion(event string, info EntryInfo, fileName string) {
+	ds := GetDSString(info.DriverStation.IsBlue, uint(info.DriverStation.Number))
+
+	versions, err := getSlotVersions(event, info.Match, ds)
+	if err != nil || len(versions) == 0 {
+		return
+	}
+
+	current := versions[len(versions)-1]
+	files := append(versionFiles(current), fileName)
+
+	filesBytes, marshalErr := json.Marshal(files)
+	if marshalErr != nil {
+		LogErrorf(marshalErr, "Problem encoding %v", files)
+		return
+	}
+
+	_, execErr := matchDB.Exec(
+		"update entry_versions set files = ? where event = ? and matchnum = ? and replay = ? and station = ? and version = ?",
+		string(filesBytes), event, info.Match.Number, info.Match.IsReplay, ds, current.Version,
+	)
+	if execErr != nil {
+		LogErrorf(execErr, "Problem adding %v to version %v of its match", fileName, current.Version)
+	}
+}
+
+// Rolls a match slot back to an earlier version. The entries of that version are moved back into Written,
+// everything else written for the slot is discarded, and the slot's row on the sheet is rewritten.
+// The rollback is itself recorded as a new version, so it can be undone the same way.
+func RollbackSlot(event string, match MatchInfo, ds string, target int, admin string) (int, error) {
+	isBlue, station, valid := parseDSString(ds)
+	if !valid {
+		return 0, errors.New("unknown driverstation " + ds)
+	}
+
+	// Keep ingestion workers off the match slot while it changes
+	key := fmt.Sprintf("%s_%v_%s", event, match.Number, ds)
+	lockSlot(key)
+	defer unlockSlot(key)
+
+	versions, err := getSlotVersions(event, match, ds)
+	if err != nil {
+		return 0, err
+	}
+
+	index := slices.IndexFunc(versions, func(version EntryVersion) bool { return version.Version == target })
+	if index == -1 {
+		return 0, fmt.Errorf("match %v %v has no version %v", match.Number, ds, target)
+	}
+	current := versions[len(versions)-1].Version
+	if target == current {
+		return 0, fmt.Errorf("version %v is already the current version", target)
+	}
+
+	files := versionFiles(versions[index])
+	written := GetSlotFiles(event, match, isBlue, station, EntryWritten)
+
+	// Make sure everything can be brought back before touching anything
+	for _, file := range files {
+		if slices.Contains(written, file) {
+			continue
+		}
+		if _, statErr := os.Stat(filepath.Join(JsonDiscardedDirectory, file)); statErr != nil {
+			return 0, errors.New(file + " is no longer in Discarded")
+		}
+	}
+
+	note := fmt.Sprintf("Rollback to version %v", target)
+
+	var discarded []string
+	for _, file := range written {
+		if slices.Contains(files, file) {
+			continue
+		}
+		if !MoveFile(filepath.Join(JsonWrittenDirectory, file), filepath.Join(JsonDiscardedDirectory, file)) {
+			return 0, errors.New("unable to move " + file + " to Discarded")
+		}
+		SetEntryState(file, EntryDiscarded)
+		discarded = append(discarded, file)
+		RecordPipelineAction(PipelineAction{Time: time.Now(), Admin: admin, Action: PipelineDiscard, File: file, From: PipelineWritten, To: PipelineDiscarded, Note: note})
+	}
+
+	var entries []StoredEntry
+	for _, file := range files {
+		if !slices.Contains(written, file) {
+			if !MoveFile(filepath.Join(JsonDiscardedDirectory, file), filepath.Join(JsonWrittenDirectory, file)) {
+				return 0, errors.New("unable to move " + file + " to Written")
+			}
+			SetEntryState(file, EntryWritten)
+			RecordPipelineAction(PipelineAction{Time: time.Now(), Admin: admin, Action: PipelineRestore, File: file, From: PipelineDiscarded, To: PipelineWritten, Note: note})
+		}
+
+		if stored, ok := GetStoredEntry(file); ok {
+			entries = append(entries, stored)
+		}
+	}
+
+	version, err := addSlotVersion(event, match, ds, files, current, VersionRollback, admin)
+	if err != nil {
+		LogErrorf(err, "Problem recording rollback of %v match %v %v", event, match.Number, ds)
+	}
+
+	LogMessagef("%v rolled %v match %v %v back to version %v", admin, event, match.Number, ds, target)
+
+	return version, rewriteSlotRow(event, match, isBlue, station, entries, discarded)
+}
+
+// Rewrites the row of a match slot on the sheet from the passed in entries, after the passed in files were discarded from it
+func rewriteSlotRow(event string, match MatchInfo, isBlue bool, station int, entries []StoredEntry, discarded []string) error {
+	if event != GetCurrentEvent() {
+		return nil // Only the current event is on the sheet
+	}
+
+	season := SeasonForEvent(event)
+
+	if !CachedConfigs.UsingMultiScouting { // Every entry has its own row, so the discarded ones are blanked and the others written back
+		blank := make([]interface{}, len(season.Headers(TabRawData)))
+		for i := range blank {
+			blank[i] = ""
+		}
+
+		var rows []SinkRow
+		for _, file := range discarded {
+			row, err := GetEntryRow(event, file)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, SinkRow{Row: row, Values: blank})
+		}
+		for _, entry := range entries {
+			row, err := GetEntryRow(event, entry.File)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, SinkRow{Row: row, Values: append(season.RawDataRow(entry.Data), "")})
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+		return WriteSinkRows(TabRawData, rows)
+	}
+
+	if !match.IsReplay && len(GetSlotFiles(event, MatchInfo{Number: match.Number, IsReplay: true}, isBlue, station, EntryWritten)) > 0 {
+		return nil // The replay's row takes precedence over the original match
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var data []MatchEntry
+	for _, entry := range entries {
+		data = append(data, entry.Data)
+	}
+	row := GetRow(data[0].Info())
+
+	if len(data) == 1 {
+		ClearMergeResult(event, match, GetDSString(isBlue, uint(station)))
+		return WriteTeamDataToRow(season, data[0], row)
+	}
+	return WriteMultiScoutedTeamDataToLine(season, event, data, row)
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Writes a 2026 entry for match 5 red1 into Written and matches.db
+func writeTestEntry(t *testing.T, fileName string, scouter string, rescouting bool) EntryInfo {
+	t.Helper()
+
+	team := &TeamData{TeamNumber: 1816, Scouter: scouter, Rescouting: rescouting}
+	team.Match.Number = 5
+	team.DriverStation.Number = 1
+
+	teamBytes, marshalErr := json.Marshal(team)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	if err := os.WriteFile(filepath.Join(JsonWrittenDirectory, fileName), teamBytes, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreMatchEntry(fileName, team, EntryWritten); err != nil {
+		t.Fatal(err)
+	}
+	return team.Info()
+}
+
+func TestRollbackSlot(t *testing.T) {
+	setupTestEnvironment(t)
+	event := "2026other" // Not the current event, so nothing is written to the sheet
+
+	writeTestEntry(t, "2026other_5_red1_1.json", "a", false)
+	writeTestEntry(t, "2026other_5_red1_2.json", "b", false)
+	info := writeTestEntry(t, "2026other_5_red1_3.json", "c", true)
+	if err := supersedeSlot(event, info, "2026other_5_red1_3.json", []string{"2026other_5_red1_1.json", "2026other_5_red1_2.json"}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestEntry(t, "2026other_5_red1_4.json", "d", false)
+	addToCurrentVersion(event, info, "2026other_5_red1_4.json")
+
+	written := func() []string {
+		files := GetSlotFiles(event, info.Match, false, 1, EntryWritten)
+		slices.Sort(files)
+		return files
+	}
+	if got := written(); !slices.Equal(got, []string{"2026other_5_red1_3.json", "2026other_5_red1_4.json"}) {
+		t.Fatalf("written before rolling back: %v", got)
+	}
+
+	version, err := RollbackSlot(event, info.Match, "red1", 1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 3 {
+		t.Errorf("rollback recorded as version %v, want 3", version)
+	}
+	if got := written(); !slices.Equal(got, []string{"2026other_5_red1_1.json", "2026other_5_red1_2.json"}) {
+		t.Errorf("written after rolling back to the original: %v", got)
+	}
+	for _, file := range []string{"2026other_5_red1_3.json", "2026other_5_red1_4.json"} {
+		if _, statErr := os.Stat(filepath.Join(JsonDiscardedDirectory, file)); statErr != nil {
+			t.Errorf("%v not discarded: %v", file, statErr)
+		}
+	}
+
+	// Rollbacks are versions too, so the rescout can be brought back
+	if _, err = RollbackSlot(event, info.Match, "red1", 2, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if got := written(); !slices.Equal(got, []string{"2026other_5_red1_3.json", "2026other_5_red1_4.json"}) {
+		t.Errorf("written after rolling forward to the rescout: %v", got)
+	}
+
+	history, err := GetSlotHistory(event, info.Match, "red1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Current != 4 || history.Versions[3].Action != VersionRollback || history.Versions[3].Supersedes != 3 {
+		t.Errorf("unexpected history %+v", history)
+	}
+
+	if _, err = RollbackSlot(event, info.Match, "red1", 4, "admin"); err == nil {
+		t.Error("rolled back to the current version")
+	}
+	if _, err = RollbackSlot(event, info.Match, "red1", 9, "admin"); err == nil {
+		t.Error("rolled back to a version that doesn't exist")
+	}
+	if _, err = RollbackSlot(event, info.Match, "purple1", 1, "admin"); err == nil {
+		t.Error("rolled back an unknown driverstation")
+	}
+}
+
+func TestRollbackSlotNeedsDiscardedFiles(t *testing.T) {
+	setupTestEnvironment(t)
+	event := "2026other"
+
+	writeTestEntry(t, "2026other_5_red1_1.json", "a", false)
+	info := writeTestEntry(t, "2026other_5_red1_2.json", "b", true)
+	if err := supersedeSlot(event, info, "2026other_5_red1_2.json", []string{"2026other_5_red1_1.json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(JsonDiscardedDirectory, "2026other_5_red1_1.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RollbackSlot(event, info.Match, "red1", 1, "admin"); err == nil {
+		t.Fatal("rolled back to a version whose files are gone")
+	}
+	if got := GetSlotFiles(event, info.Match, false, 1, EntryWritten); !slices.Equal(got, []string{"2026other_5_red1_2.json"}) {
+		t.Errorf("a failed rollback changed what was written: %v", got)
+	}
+}
+
+func TestSupersedeSlotKeepsFilesWhenHistoryFails(t *testing.T) {
+	setupTestEnvironment(t)
+	event := "2026other"
+
+	writeTestEntry(t, "2026other_5_red1_1.json", "a", false)
+	info := writeTestEntry(t, "2026other_5_red1_2.json", "b", true)
+
+	if _, err := matchDB.Exec("drop table entry_versions"); err != nil {
+		t.Fatal(err)
+	}
+	if err := supersedeSlot(event, info, "2026other_5_red1_2.json", []string{"2026other_5_red1_1.json"}); err == nil {
+		t.Fatal("superseded without recording a version")
+	}
+
+	if _, err := os.Stat(filepath.Join(JsonWrittenDirectory, "2026other_5_red1_1.json")); err != nil {
+		t.Errorf("superseded file moved without its history: %v", err)
+	}
+}
+
+func TestRollbackRewritesOnlyItsSlotWhenSingleScouting(t *testing.T) {
+	setupTestEnvironment(t)
+	CachedConfigs.UsingMultiScouting = false
+	sink := &recordingSink{}
+	setupTestSink(t, sink)
+	event := GetCurrentEvent()
+
+	// Another slot's entry comes between them on the sheet
+	files := []string{event + "_5_red1_1.json", event + "_6_red1_2.json", event + "_5_red1_3.json"}
+	for _, file := range files {
+		if _, err := GetEntryRow(event, file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestEntry(t, files[0], "a", false)
+	info := writeTestEntry(t, files[2], "b", true)
+	if err := supersedeSlot(event, info, files[2], files[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	sink.WriteRows(TabRawData, []SinkRow{{Row: 50, Values: []interface{}{"untouched"}}})
+	if _, err := RollbackSlot(event, info.Match, "red1", 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sink.row(TabRawData, 2); len(got) == 0 {
+		t.Error("restored entry wasn't written back to its row")
+	}
+	if got := sink.row(TabRawData, 4); got == nil || len(got) != 0 {
+		t.Errorf("discarded entry's row reads %v, want it blank", got)
+	}
+	if got := sink.row(TabRawData, 3); got != nil {
+		t.Errorf("another slot's row was rewritten with %v", got)
+	}
+	if got := sink.row(TabRawData, 50); !slices.Equal(got, []string{"untouched"}) {
+		t.Errorf("row 50 reads %v, the whole sheet was rewritten", got)
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+// An output sink that POSTs every write to a URL, for anything that wants to follow along with the data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// How long a webhook has to answer before the write counts as failed
+const kWebhookTimeout = 10 * time.Second
+
+// What a webhook sink POSTs for every write
+type WebhookPayload struct {
+	Event  string          `json:"Event"`            // The event key
+	Action string          `json:"Action"`           // write, append or clear
+	Tab    string          `json:"Tab,omitempty"`    // The tab written to, for writes and appends
+	Tabs   []string        `json:"Tabs,omitempty"`   // The tabs cleared, for clears
+	Rows   []SinkRow       `json:"Rows,omitempty"`   // The rows written, for writes
+	Values [][]interface{} `json:"Values,omitempty"` // The rows added after the last one with data, for appends
+}
+
+// POSTs a WebhookPayload as json to its URL. Anything but a 2xx answer is a failed write.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// Creates a webhook sink posting to the passed in URL
+func newWebhookSink(url string) *webhookSink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: kWebhookTimeout}}
+}
+
+// Sends one payload to the webhook
+func (sink *webhookSink) post(payload WebhookPayload) error {
+	payload.Event = GetCurrentEvent()
+
+	body, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	response, postErr := sink.client.Post(sink.url, "application/json", bytes.NewReader(body))
+	if postErr != nil {
+		return postErr
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %v", response.Status)
+	}
+	return nil
+}
+
+// Sends the rows to write over
+func (sink *webhookSink) WriteRows(tab string, rows []SinkRow) error {
+	return sink.post(WebhookPayload{Action: "write", Tab: tab, Rows: rows})
+}
+
+// Sends the rows to append, leaving it to the receiver to find where the data ends
+func (sink *webhookSink) AppendRows(tab string, from int, values [][]interface{}) error {
+	return sink.post(WebhookPayload{Action: "append", Tab: tab, Values: values})
+}
+
+// Sends which tabs to clear
+func (sink *webhookSink) ClearTabs(tabs []string) error {
+	return sink.post(WebhookPayload{Action: "clear", Tabs: tabs})
+}
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 	"slices"
 	"syscall"
-	"time"
 
 	"github.com/robfig/cron/v3"
 	"golang.org/x/crypto/acme/autocert"
@@ -50,7 +49,6 @@ func main() {
 
 	internal.TotalSetup(publicHosting)
 
-	internal.WriteConditionalFormatting()
 	if isSetup { // Exit if only in setup mode
 		os.Exit(1)
 	}
@@ -59,10 +57,14 @@ func main() {
 	internal.InitScoutDB()
 	internal.InitAuthDB()
 	internal.InitUserDB()
+	internal.InitMatchDB()
+	internal.InitOutputSinks()
+	internal.ProvisionIfChanged()
+	internal.WriteSheetHeaders()
 
 	internal.StoreTeams()
 
-	// Write all match numbers to the sheet with a 1 minute cooldown to avoid rate limiting
+	// Write all match numbers to the sheet. Writes wait for room in the sheets quota on their own.
 	if slices.Contains(os.Args, "matches") {
 		var usingRemainder bool = false
 
@@ -77,7 +79,6 @@ func main() {
 
 		for i := 1; i <= blocks*50; i += 50 {
 			internal.FillMatches(i, i+49)
-			time.Sleep(1 * time.Minute)
 		}
 
 		if usingRemainder {
@@ -152,6 +153,9 @@ func main() {
 	internal.LogMessage("Server Successfully Set Up! [ctrl+c to cancel]")
 
 	go internal.RunServerLoop()
+	go internal.RunSheetReconciliation()
+	go internal.RunCoverageRefresh()
+	go internal.RunReliabilityRefresh()
 
 	/// Graceful shutdown
 
@@ -162,5 +166,8 @@ func main() {
 	// Wait for termination signal
 	<-signalCh
 
+	// Send anything still waiting to go to the sheet
+	internal.FlushOutputSinks()
+
 	// no need to os.exit, since the main thread exits here all the goroutines will shut down
 }